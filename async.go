@@ -0,0 +1,118 @@
+package sl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProvideFuncEagerAsync is like [ProvideFunc] but starts creating the value
+// right away in a background goroutine, this is useful for services that take
+// a long time to warm up. Using the slot with [Use] waits for the value to be
+// created while [UseReady] never blocks.
+//
+// The value is created only once, if "createFunc" fails its error is returned
+// by every use of the slot.
+func ProvideFuncEagerAsync[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error)) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject async provider`, typeName)
+
+	var once sync.Once
+	var value T
+	var err error
+
+	entry := &slotEntry{
+		typeName: typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) {
+			once.Do(func() { value, err = createFunc(l) })
+			return value, err
+		},
+		asyncDone: make(chan struct{}),
+	}
+
+	if !l.setProvider(slotKey, entry) {
+		return
+	}
+
+	root := &ServiceLocator{locatorState: l.locatorState}
+	go func() {
+		_, entry.asyncErr = entry.ensureConfigured(root)
+		close(entry.asyncDone)
+	}()
+}
+
+// UseReady resolves a slot without blocking, the returned boolean tells if the
+// value is ready: slots provided with [ProvideFuncEagerAsync] are ready when
+// their background creation ended (successfully or not) and lazy slots are
+// ready once configured. When not ready this returns the zero value and no
+// error, an error is returned if the slot is missing or failed to be created.
+func UseReady[T any](l *ServiceLocator, slotKey slot[T]) (T, bool, error) {
+	l.mu.Lock()
+	slot, _, ok := l.lookupProvider(slotKey)
+	if !ok {
+		l.mu.Unlock()
+		return zero[T](), false, notFoundError(slotKey)
+	}
+
+	configured := slot.configured
+	l.mu.Unlock()
+
+	if !configured && slot.asyncDone != nil {
+		select {
+		case <-slot.asyncDone:
+			if slot.asyncErr != nil {
+				return zero[T](), true, slot.asyncErr
+			}
+		default:
+			return zero[T](), false, nil
+		}
+	} else if !configured && slot.extractFunc == nil {
+		return zero[T](), false, nil
+	}
+
+	v, err := useSlotValue(l, slotKey)
+	return v, true, err
+}
+
+// waitPollInterval is how often [UseWait] checks if the slot got provided
+const waitPollInterval = 5 * time.Millisecond
+
+// UseWait is like [Use] but if the slot has no provider yet it waits up to
+// "timeout" for one to be registered, for example by a plugin loaded in the
+// background. The error returned when the slot never gets provided wraps both
+// [context.DeadlineExceeded] and [ErrSlotNotFound].
+//
+// This only waits for the registration, the slot is then resolved like by
+// [Use].
+func UseWait[T any](l *ServiceLocator, slotKey slot[T], timeout time.Duration) (T, error) {
+	provided := func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		_, _, ok := l.lookupProvider(slotKey)
+		return ok
+	}
+
+	if !provided() {
+		l.logf(`[slot: %s] waiting up to %v for a provider`, slotName(slotKey), timeout)
+
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+
+		ticker := time.NewTicker(waitPollInterval)
+		defer ticker.Stop()
+
+		for !provided() {
+			select {
+			case <-ticker.C:
+			case <-deadline.C:
+				if !provided() {
+					return zero[T](), fmt.Errorf(`%w: %w after %v`, context.DeadlineExceeded, notFoundError(slotKey), timeout)
+				}
+			}
+		}
+	}
+
+	return Use(l, slotKey)
+}
@@ -0,0 +1,84 @@
+package sl_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestProvideFuncEagerAsync(t *testing.T) {
+	l := sl.New()
+
+	release := make(chan struct{})
+	created := 0
+	sl.ProvideFuncEagerAsync(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		<-release
+		created++
+		return &Config{Foo: "model"}, nil
+	})
+
+	_, ready, err := sl.UseReady(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, ready, false)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	config := sl.MustUse(l, ConfigSlot)
+	assert.Equal(t, config.Foo, "model")
+
+	readyConfig, ready, err := sl.UseReady(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, ready, true)
+	assert.Equal(t, readyConfig, config)
+	assert.Equal(t, created, 1)
+}
+
+func TestProvideFuncEagerAsyncError(t *testing.T) {
+	l := sl.New()
+
+	errLoad := errors.New("load error")
+	sl.ProvideFuncEagerAsync(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		time.Sleep(10 * time.Millisecond)
+		return nil, errLoad
+	})
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, errLoad))
+
+	for {
+		_, ready, err := sl.UseReady(l, ConfigSlot)
+		if ready {
+			assert.Assert(t, errors.Is(err, errLoad))
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_, ready, err := sl.UseReady(l, LoggerSlot)
+	assert.Equal(t, ready, false)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+func TestUseWait(t *testing.T) {
+	l := sl.New()
+
+	_, err := sl.UseWait(l, ConfigSlot, 10*time.Millisecond)
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		sl.Provide(l, ConfigSlot, &Config{Foo: "late"})
+	}()
+
+	config, err := sl.UseWait(l, ConfigSlot, time.Second)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "late")
+}
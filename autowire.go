@@ -0,0 +1,104 @@
+package sl
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// typeBinding is the slot bound to a type with [BindType]
+type typeBinding struct {
+	slotKey any
+	resolve func(*ServiceLocator) (any, error)
+}
+
+// BindType binds the type "T" to the given slot, so that reflection based
+// functions like [Autowire] and [ResolveType] can resolve values of type "T"
+// from this slot. Interface types are bound as themselves and not as the type
+// of their values.
+//
+// Each type can be bound to only one slot per locator, this panics if "T" is
+// already bound to another slot of this locator. Child scopes can bind a type
+// again to shadow the binding of their parent.
+func BindType[T any](l *ServiceLocator, slotKey slot[T]) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.typeBindings == nil {
+		l.typeBindings = map[reflect.Type]typeBinding{}
+	}
+
+	if binding, ok := l.typeBindings[t]; ok && binding.slotKey != any(slotKey) {
+		panic(fmt.Sprintf(`type %s is already bound to another slot`, t))
+	}
+
+	l.typeBindings[t] = typeBinding{
+		slotKey: slotKey,
+		resolve: func(l *ServiceLocator) (any, error) {
+			return Use(l, slotKey)
+		},
+	}
+}
+
+// lookupTypeBinding searches the binding for the given type in this locator
+// and then in its parent scopes. The lock of the locator must be held.
+func (l *ServiceLocator) lookupTypeBinding(t reflect.Type) (typeBinding, bool) {
+	for current := l; current != nil; current = current.parent {
+		if binding, ok := current.typeBindings[t]; ok {
+			return binding, true
+		}
+	}
+
+	return typeBinding{}, false
+}
+
+// ResolveType resolves a value of type "t" from the slot bound to it with
+// [BindType], returning an error if no slot is bound to "t". This is the
+// building block of reflection based functions like [Autowire].
+func ResolveType(l *ServiceLocator, t reflect.Type) (any, error) {
+	l.mu.Lock()
+	binding, ok := l.lookupTypeBinding(t)
+	l.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf(`no slot bound to type %s`, t)
+	}
+
+	return binding.resolve(l)
+}
+
+// Autowire populates the fields of the struct pointed by "target" tagged with
+// `sl:"inject"` resolving them from the slots bound to their types with
+// [BindType]. Unexported and untagged fields are skipped.
+//
+// This returns an error naming the first field whose type has no bound slot
+// or whose slot fails to resolve, or if "target" is nil.
+func Autowire[T any](l *ServiceLocator, target *T) error {
+	if target == nil {
+		return fmt.Errorf(`cannot autowire nil pointer to %s`, getTypeName[T]())
+	}
+
+	v := reflect.ValueOf(target).Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf(`cannot autowire non-struct type %s`, v.Type())
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("sl") != "inject" {
+			continue
+		}
+
+		value, err := ResolveType(l, field.Type)
+		if err != nil {
+			return fmt.Errorf(`autowiring field %s: %w`, field.Name, err)
+		}
+
+		if value != nil {
+			v.Field(i).Set(reflect.ValueOf(value))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package sl_test
+
+import (
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+type AutowiredService struct {
+	Config  *Config `sl:"inject"`
+	Greeter Greeter `sl:"inject"`
+	Name    string
+
+	logger *log.Logger `sl:"inject"`
+}
+
+func TestAutowire(t *testing.T) {
+	l := sl.New()
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	greeter := sl.Provide[Greeter](l, GreeterSlot, &EnglishGreeter{Name: "World"})
+
+	sl.BindType(l, ConfigSlot)
+
+	service := &AutowiredService{Name: "service"}
+	assert.Error(t, sl.Autowire(l, service), "autowiring field Greeter: no slot bound to type sl_test.Greeter")
+
+	sl.BindType(l, GreeterSlot)
+
+	service = &AutowiredService{Name: "service"}
+	assert.NilError(t, sl.Autowire(l, service))
+	assert.Equal(t, service.Config, config)
+	assert.Equal(t, service.Greeter, greeter)
+	assert.Equal(t, service.Name, "service")
+	assert.Assert(t, service.logger == nil)
+
+	assert.Error(t, sl.Autowire(l, (*AutowiredService)(nil)), "cannot autowire nil pointer to sl_test.AutowiredService")
+}
+
+func TestResolveType(t *testing.T) {
+	l := sl.New()
+
+	greeter := sl.Provide[Greeter](l, GreeterSlot, &EnglishGreeter{Name: "World"})
+	sl.BindType(l, GreeterSlot)
+	sl.BindType(l, GreeterSlot)
+
+	greeterType := reflect.TypeOf((*Greeter)(nil)).Elem()
+
+	value, err := sl.ResolveType(l, greeterType)
+	assert.NilError(t, err)
+	assert.Equal(t, value, greeter)
+
+	_, err = sl.ResolveType(l, reflect.TypeOf(&EnglishGreeter{}))
+	assert.Error(t, err, "no slot bound to type *sl_test.EnglishGreeter")
+
+	scope := l.Scope()
+	otherSlot := sl.NewSlot[Greeter]()
+	sl.Provide[Greeter](scope, otherSlot, &ItalianGreeter{Name: "Mondo"})
+	sl.BindType(scope, otherSlot)
+
+	value, err = sl.ResolveType(scope, greeterType)
+	assert.NilError(t, err)
+	assert.Equal(t, value.(Greeter).Greet(), "Ciao Mondo")
+
+	defer func() {
+		assert.Equal(t, recover(), "type sl_test.Greeter is already bound to another slot")
+	}()
+	sl.BindType(l, otherSlot)
+}
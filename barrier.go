@@ -0,0 +1,57 @@
+package sl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrBarrier is returned (wrapped) when resolving a slot marked with
+// [ServiceLocator.Barrier] before the barrier is released.
+var ErrBarrier = errors.New(`slot used before barrier release`)
+
+// Barrier marks the given slots as unusable until [ServiceLocator.ReleaseBarrier]
+// is called, resolving them from this locator or its child scopes returns an
+// error wrapping [ErrBarrier]. This enforces initialization phases, for
+// example to forbid using services depending on the configuration before it
+// is loaded.
+//
+// This panics if a key is not a slot.
+func (l *ServiceLocator) Barrier(keys ...any) {
+	for _, key := range keys {
+		if _, ok := slotKeyTypeName(key); !ok {
+			panic(fmt.Sprintf(`key of type %T is not a slot`, key))
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.barrier == nil {
+		l.barrier = map[any]bool{}
+	}
+	for _, key := range keys {
+		l.barrier[key] = true
+	}
+}
+
+// ReleaseBarrier lifts the barrier of this locator, the slots marked with
+// [ServiceLocator.Barrier] can be used again.
+func (l *ServiceLocator) ReleaseBarrier() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.barrier = nil
+}
+
+// checkBarrier returns an error if the given slot is behind the barrier of
+// this locator or of one of its parent scopes. The lock of the locator must be
+// held.
+func (l *ServiceLocator) checkBarrier(slotKey any, typeName string) error {
+	for current := l; current != nil; current = current.parent {
+		if current.barrier[slotKey] {
+			return fmt.Errorf(`%w: type %s`, ErrBarrier, typeName)
+		}
+	}
+
+	return nil
+}
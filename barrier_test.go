@@ -0,0 +1,72 @@
+package sl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestBarrier(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		config, err := sl.Use(l, ConfigSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExampleService{Bar: config.Foo}, nil
+	})
+
+	l.Barrier(ConfigSlot)
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrBarrier))
+
+	child := l.Scope()
+	_, err = sl.Use(child, ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrBarrier))
+
+	l.ReleaseBarrier()
+
+	config, err := sl.Use(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+	assert.Equal(t, sl.MustUse(child, ExampleServiceSlot).Bar, "foo")
+
+	defer func() {
+		assert.Equal(t, recover(), "key of type string is not a slot")
+	}()
+	l.Barrier("config")
+}
+
+func TestBarrierValidateWarmUp(t *testing.T) {
+	l := sl.New()
+
+	configured := 0
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		configured++
+		return &ExampleService{}, nil
+	})
+
+	l.Barrier(ExampleServiceSlot)
+
+	err := l.Validate(ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrBarrier))
+
+	err = l.WarmUp()
+	assert.Assert(t, errors.Is(err, sl.ErrBarrier))
+
+	err = l.ForceOrder([]string{"*sl_test.ExampleService"})
+	assert.Assert(t, errors.Is(err, sl.ErrBarrier))
+
+	assert.Equal(t, configured, 0)
+
+	l.ReleaseBarrier()
+
+	assert.NilError(t, l.Validate(ExampleServiceSlot))
+	assert.Equal(t, configured, 1)
+}
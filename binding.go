@@ -0,0 +1,82 @@
+package sl
+
+// Binding is the handle returned by [ProvideRebindable] to swap the value of
+// a slot at runtime.
+type Binding[T any] struct {
+	l     *ServiceLocator
+	entry *slotEntry
+}
+
+// ProvideRebindable is like [Provide] but returns a [Binding] to replace the
+// value of the slot later, every [Use] of the slot returns the currently
+// bound value. This is useful for hot-reloading plugins.
+//
+// Lazy slots that already used the previous value keep it, so dependents that
+// should pick up the new value must be reset (see [ResetCascade]) or resolve
+// the slot every time (see [Getter]).
+//
+// The initial value is stored like by [Provide], so it goes through the
+// provide interceptors and the auto-closer. This returns nil if the slot can't
+// be provided, for example because the locator is sealed (see
+// [ServiceLocator.Seal]).
+func ProvideRebindable[T any](l *ServiceLocator, slotKey slot[T], initial T) *Binding[T] {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] provided rebindable value of type %T`, typeName, initial)
+
+	entry, _ := valueEntry(l, typeName, initial)
+	if !l.setProvider(slotKey, entry) {
+		return nil
+	}
+
+	return &Binding[T]{l, entry}
+}
+
+// Get returns the currently bound value
+func (b *Binding[T]) Get() T {
+	b.l.mu.Lock()
+	defer b.l.mu.Unlock()
+
+	v, _ := assertSlotValue[T](b.entry.value)
+	return v
+}
+
+// Set atomically replaces the bound value, this is safe to call concurrently
+// with uses of the slot.
+func (b *Binding[T]) Set(value T) {
+	b.l.logf(`[slot: %s] rebound to value of type %T`, b.entry.typeName, value)
+
+	b.l.mu.Lock()
+	b.entry.value = value
+	b.l.mu.Unlock()
+
+	b.l.emit(Event{Kind: EventProvide, TypeName: b.entry.typeName})
+}
+
+// Service bundles a locator and one of its slots, so it can be stored as a
+// struct field and used without passing both around. Create one with [Bind].
+type Service[T any] struct {
+	l       *ServiceLocator
+	slotKey slot[T]
+}
+
+// Bind returns a [Service] resolving "slotKey" from "l", the slot is resolved
+// on each call so it doesn't need to be provided yet.
+func Bind[T any](l *ServiceLocator, slotKey slot[T]) Service[T] {
+	return Service[T]{l, slotKey}
+}
+
+// Get resolves the slot like [Use]
+func (s Service[T]) Get() (T, error) {
+	return Use(s.l, s.slotKey)
+}
+
+// MustGet resolves the slot like [MustUse]
+func (s Service[T]) MustGet() T {
+	return MustUse(s.l, s.slotKey)
+}
+
+// Set replaces the value of the slot like [Override]
+func (s Service[T]) Set(value T) {
+	Override(s.l, s.slotKey, value)
+}
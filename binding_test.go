@@ -0,0 +1,94 @@
+package sl_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestProvideRebindable(t *testing.T) {
+	l := sl.New()
+
+	binding := sl.ProvideRebindable[Greeter](l, GreeterSlot, &EnglishGreeter{})
+	getter := sl.MustGetter(l, GreeterSlot)
+
+	_, isEnglish := getter().(*EnglishGreeter)
+	assert.Assert(t, isEnglish)
+
+	binding.Set(&ItalianGreeter{})
+
+	_, isItalian := getter().(*ItalianGreeter)
+	assert.Assert(t, isItalian)
+	assert.Equal(t, binding.Get(), sl.MustUse(l, GreeterSlot))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			binding.Set(&EnglishGreeter{})
+		}()
+		go func() {
+			defer wg.Done()
+			sl.MustUse(l, GreeterSlot)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProvideRebindableEdgeCases(t *testing.T) {
+	l := sl.New()
+
+	binding := sl.ProvideRebindable[Greeter](l, GreeterSlot, nil)
+	assert.Assert(t, binding.Get() == nil)
+
+	intercepted := 0
+	l.AddProvideInterceptor(func(typeName string, value any) any {
+		intercepted++
+		return value
+	})
+
+	sl.ProvideRebindable(l, ConfigSlot, &Config{})
+	assert.Equal(t, intercepted, 1)
+
+	assert.Assert(t, sl.ProvideRebindable(l, sl.LocatorSlot, l) == nil)
+
+	l.Seal()
+	assert.Assert(t, sl.ProvideRebindable(l, ExampleServiceSlot, &ExampleService{}) == nil)
+}
+
+// server stores its dependencies as services
+type server struct {
+	config sl.Service[*Config]
+}
+
+func TestBind(t *testing.T) {
+	l := sl.New()
+
+	var buf bytes.Buffer
+	defer l.SetLogger(log.New(&buf, "", 0))()
+
+	s := server{config: sl.Bind(l, ConfigSlot)}
+
+	_, err := s.config.Get()
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+	assert.Equal(t, s.config.MustGet().Foo, "foo")
+
+	s.config.Set(&Config{Foo: "bar"})
+	assert.Assert(t, strings.Contains(buf.String(), "[slot: *sl_test.Config] override with value of type *sl_test.Config\n"))
+
+	config, err := s.config.Get()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "bar")
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+}
@@ -0,0 +1,85 @@
+package sl
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// packagePathRegex matches the import path prefix of qualified type names
+var packagePathRegex = regexp.MustCompile(`[\w.\-~]+/`)
+
+// slotKeyTypeName returns the type name of the values of a slot key given as
+// an "any" using reflection, the name is built from the type arguments of the
+// slot type with import paths shortened to their last element and is qualified
+// by the label of the slot like for [NewSlotNamed].
+func slotKeyTypeName(slotKey any) (string, bool) {
+	t := reflect.TypeOf(slotKey)
+	if t == nil || t.PkgPath() != reflect.TypeOf(LocatorSlot).PkgPath() {
+		return "", false
+	}
+
+	name := t.Name()
+	if !strings.HasPrefix(name, "slot[") || !strings.HasSuffix(name, "]") {
+		return "", false
+	}
+
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "slot["), "]")
+	name = packagePathRegex.ReplaceAllString(name, "")
+
+	symbolType := reflect.TypeOf((*symbol)(nil))
+	if label := reflect.ValueOf(slotKey).Convert(symbolType).Interface().(*symbol).label; label != "" {
+		name = fmt.Sprintf(`%q (%s)`, label, name)
+	}
+
+	return name, true
+}
+
+// ProvideFuncAll registers many lazy providers at once, this is mostly useful
+// for generated code. Each key of "entries" must be a slot created with
+// [NewSlot] and each "createFunc" must return values of the type of its slot,
+// this is not checked at compile time so prefer [ProvideFunc] when writing
+// code by hand.
+//
+// Type names are derived from the slot types with reflection, so they can
+// differ from the ones used by [ProvideFunc] for packages whose name is not
+// the last element of their import path. Entries are registered ordered by
+// type name, slots with the same type name in the order they were created.
+//
+// This panics if a key is not a slot.
+func ProvideFuncAll(l *ServiceLocator, entries map[any]func(*ServiceLocator) (any, error)) {
+	type namedEntry struct {
+		typeName   string
+		slotKey    any
+		createFunc func(*ServiceLocator) (any, error)
+	}
+
+	named := make([]namedEntry, 0, len(entries))
+	for slotKey, createFunc := range entries {
+		typeName, ok := slotKeyTypeName(slotKey)
+		if !ok {
+			panic(fmt.Sprintf(`key of type %T is not a slot`, slotKey))
+		}
+
+		named = append(named, namedEntry{typeName, slotKey, createFunc})
+	}
+
+	sort.SliceStable(named, func(i, j int) bool {
+		if named[i].typeName != named[j].typeName {
+			return named[i].typeName < named[j].typeName
+		}
+
+		return keySeq(named[i].slotKey) < keySeq(named[j].slotKey)
+	})
+
+	for _, e := range named {
+		l.logf(`[slot: %s] inject lazy provider`, e.typeName)
+
+		l.setProvider(e.slotKey, &slotEntry{
+			typeName:      e.typeName,
+			configureFunc: e.createFunc,
+		})
+	}
+}
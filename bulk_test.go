@@ -0,0 +1,90 @@
+package sl_test
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestProvideFuncAll(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFuncAll(l, map[any]func(*sl.ServiceLocator) (any, error){
+		ConfigSlot: func(l *sl.ServiceLocator) (any, error) {
+			return &Config{Foo: "foo"}, nil
+		},
+		LoggerSlot: func(l *sl.ServiceLocator) (any, error) {
+			return log.New(os.Stderr, "", 0), nil
+		},
+		ExampleServiceSlot: func(l *sl.ServiceLocator) (any, error) {
+			config, logger, err := sl.Use2(l, ConfigSlot, LoggerSlot)
+			if err != nil {
+				return nil, err
+			}
+
+			return &ExampleService{Bar: config.Foo + " baz", Logger: logger}, nil
+		},
+	})
+
+	assert.DeepEqual(t, l.UnusedSlots(), []string{
+		"*go-sl_test.Config",
+		"*go-sl_test.ExampleService",
+		"*log.Logger",
+	})
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, "foo baz")
+
+	defer func() {
+		assert.Equal(t, recover(), "key of type string is not a slot")
+	}()
+	sl.ProvideFuncAll(l, map[any]func(*sl.ServiceLocator) (any, error){
+		"config": nil,
+	})
+}
+
+func TestProvideFuncAllWrongType(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFuncAll(l, map[any]func(*sl.ServiceLocator) (any, error){
+		ConfigSlot: func(l *sl.ServiceLocator) (any, error) {
+			return Config{Foo: "foo"}, nil
+		},
+		GreeterSlot: func(l *sl.ServiceLocator) (any, error) {
+			return nil, nil
+		},
+	})
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Error(t, err, "slot expects *sl_test.Config but provider returned sl_test.Config")
+
+	greeter, err := sl.Use(l, GreeterSlot)
+	assert.NilError(t, err)
+	assert.Assert(t, greeter == nil)
+}
+
+func TestProvideFuncAllSameTypeName(t *testing.T) {
+	firstSlot := sl.NewSlot[string]()
+	secondSlot := sl.NewSlot[string]()
+
+	for i := 0; i < 20; i++ {
+		l := sl.New()
+		l.SetDeterministic(true)
+
+		built := []string{}
+		sl.ProvideFuncAll(l, map[any]func(*sl.ServiceLocator) (any, error){
+			secondSlot: func(l *sl.ServiceLocator) (any, error) {
+				built = append(built, "second")
+				return "second", nil
+			},
+			firstSlot: func(l *sl.ServiceLocator) (any, error) {
+				built = append(built, "first")
+				return "first", nil
+			},
+		})
+
+		assert.NilError(t, l.WarmUp())
+		assert.DeepEqual(t, built, []string{"first", "second"})
+	}
+}
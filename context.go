@@ -0,0 +1,69 @@
+package sl
+
+import (
+	"context"
+	"fmt"
+)
+
+// contextView returns a view of this locator sharing the same state that
+// carries the given context, see [UseContext].
+func (l *ServiceLocator) contextView(ctx context.Context) *ServiceLocator {
+	return &ServiceLocator{
+		locatorState: l.locatorState,
+		dependent:    l.dependent,
+		ctx:          ctx,
+		configuring:  l.configuring,
+	}
+}
+
+// ProvideFromContext injects in "slotKey" a value computed with "extract"
+// from the context of each resolution, the value is never cached. This is
+// useful for request scoped values like the current user or a trace id.
+//
+// This kind of slots can only be resolved with [UseContext] (also indirectly
+// from the "createFunc" of slots provided with [ProvideScoped] resolved with
+// [UseContext] from a per-request scope), using them with [Use] returns an
+// error. Using them while configuring any other lazy slot also returns an
+// error, as its value is cached and would leak the values of the first
+// context to every later caller.
+func ProvideFromContext[T any](l *ServiceLocator, slotKey slot[T], extract func(context.Context) (T, error)) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject context provider`, typeName)
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:    typeName,
+		extractFunc: func(ctx context.Context) (any, error) { return extract(ctx) },
+	})
+}
+
+// UseContext is the same as [Use] but resolves the slot with the given
+// context, this is needed to use slots provided with [ProvideFromContext].
+//
+// The context is also passed along to the "createFunc" of lazy slots
+// configured during this call, but only slots provided with [ProvideScoped]
+// can use context derived slots (see [ProvideFromContext]).
+func UseContext[T any](ctx context.Context, l *ServiceLocator, slotKey slot[T]) (T, error) {
+	return useSlotValue(l.contextView(ctx), slotKey)
+}
+
+// extractSlotValue computes the value of a slot provided with
+// [ProvideFromContext] from the context of "l".
+func extractSlotValue[T any](l *ServiceLocator, slot *slotEntry) (T, error) {
+	if l.ctx == nil {
+		return zero[T](), fmt.Errorf(`slot of type %s is derived from the context, use it with UseContext`, slot.typeName)
+	}
+
+	// the values of other lazy slots are shared by every later caller
+	for frame := l.configuring; frame != nil; frame = frame.outer {
+		if !frame.entry.scoped {
+			return zero[T](), fmt.Errorf(`slot of type %s is derived from the context and can't be used to configure %s, provide it with ProvideScoped`, slot.typeName, frame.entry.typeName)
+		}
+	}
+
+	v, err := slot.extractFunc(l.ctx)
+	if err != nil {
+		return zero[T](), fmt.Errorf(`extracting %s: %w`, slot.typeName, err)
+	}
+
+	return assertSlotValue[T](v)
+}
@@ -0,0 +1,96 @@
+package sl_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+type userKey struct{}
+
+func TestProvideFromContext(t *testing.T) {
+	userSlot := sl.NewSlot[string]()
+	greetingSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	sl.ProvideFromContext(l, userSlot, func(ctx context.Context) (string, error) {
+		user, ok := ctx.Value(userKey{}).(string)
+		if !ok {
+			return "", errors.New("no user")
+		}
+
+		return user, nil
+	})
+	sl.ProvideScoped(l, greetingSlot, func(l *sl.ServiceLocator) (string, error) {
+		user, err := sl.Use(l, userSlot)
+		if err != nil {
+			return "", err
+		}
+
+		return "Hello " + user, nil
+	})
+
+	_, err := sl.Use(l, userSlot)
+	assert.ErrorContains(t, err, "use it with UseContext")
+
+	_, err = sl.UseContext(context.Background(), l, userSlot)
+	assert.ErrorContains(t, err, "no user")
+
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	bob := context.WithValue(context.Background(), userKey{}, "bob")
+
+	user, err := sl.UseContext(alice, l, userSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, user, "alice")
+
+	user, err = sl.UseContext(bob, l, userSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, user, "bob")
+
+	greeting, err := sl.UseContext(alice, l.Scope(), greetingSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, greeting, "Hello alice")
+}
+
+func TestProvideFromContextCached(t *testing.T) {
+	userSlot := sl.NewSlot[string]()
+	greetingSlot := sl.NewSlot[string]()
+	scopedGreetingSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	sl.ProvideFromContext(l, userSlot, func(ctx context.Context) (string, error) {
+		user, _ := ctx.Value(userKey{}).(string)
+		return user, nil
+	})
+	greet := func(l *sl.ServiceLocator) (string, error) {
+		user, err := sl.Use(l, userSlot)
+		if err != nil {
+			return "", err
+		}
+
+		return "hi " + user, nil
+	}
+	sl.ProvideFunc(l, greetingSlot, greet)
+	sl.ProvideScoped(l, scopedGreetingSlot, greet)
+
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	bob := context.WithValue(context.Background(), userKey{}, "bob")
+
+	_, err := sl.UseContext(alice, l, greetingSlot)
+	assert.ErrorContains(t, err, "can't be used to configure string")
+	_, err = sl.UseContext(bob, l, greetingSlot)
+	assert.ErrorContains(t, err, "can't be used to configure string")
+
+	greeting, err := sl.UseContext(alice, l.Scope(), scopedGreetingSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, greeting, "hi alice")
+
+	greeting, err = sl.UseContext(bob, l.Scope(), scopedGreetingSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, greeting, "hi bob")
+}
@@ -0,0 +1,155 @@
+package sl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HookDiff is a hook with a different number of listeners in two locators,
+// see [Diff]
+type HookDiff struct {
+	TypeName   string
+	ListenersA int
+	ListenersB int
+}
+
+// DiffResult is the structural difference between two locators computed by
+// [Diff]
+type DiffResult struct {
+	// OnlyInA are the type names of the slots registered only in the first
+	// locator, a name is repeated if more slots share it
+	OnlyInA []string
+
+	// OnlyInB are the type names of the slots registered only in the second
+	// locator, a name is repeated if more slots share it
+	OnlyInB []string
+
+	// Hooks are the hooks with a different number of listeners, a hook
+	// missing from a locator has zero listeners
+	Hooks []HookDiff
+}
+
+// Empty tells if the two locators have the same structure
+func (d DiffResult) Empty() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && len(d.Hooks) == 0
+}
+
+// String formats the difference with a line for each slot or hook, slots only
+// in the first locator start with "-" and slots only in the second one start
+// with "+".
+func (d DiffResult) String() string {
+	var sb strings.Builder
+
+	for _, typeName := range d.OnlyInA {
+		fmt.Fprintf(&sb, "- slot %s\n", typeName)
+	}
+	for _, typeName := range d.OnlyInB {
+		fmt.Fprintf(&sb, "+ slot %s\n", typeName)
+	}
+	for _, h := range d.Hooks {
+		fmt.Fprintf(&sb, "~ hook %s: %d != %d listeners\n", h.TypeName, h.ListenersA, h.ListenersB)
+	}
+
+	return sb.String()
+}
+
+// Diff compares the slots and hooks registered in "a" and "b" (not including
+// their parent scopes) to detect drifts in the wiring of an application, for
+// example between environments. As values are opaque only the structure is
+// compared: slots by type name and hooks by their number of listeners.
+//
+// This doesn't configure any slot.
+func Diff(a, b *ServiceLocator) DiffResult {
+	slotsA, hooksA := a.structure()
+	slotsB, hooksB := b.structure()
+
+	typeNames := map[string]bool{}
+	for typeName := range slotsA {
+		typeNames[typeName] = true
+	}
+	for typeName := range slotsB {
+		typeNames[typeName] = true
+	}
+
+	result := DiffResult{OnlyInA: []string{}, OnlyInB: []string{}, Hooks: []HookDiff{}}
+	for _, typeName := range sortedKeys(typeNames) {
+		for i := slotsB[typeName]; i < slotsA[typeName]; i++ {
+			result.OnlyInA = append(result.OnlyInA, typeName)
+		}
+		for i := slotsA[typeName]; i < slotsB[typeName]; i++ {
+			result.OnlyInB = append(result.OnlyInB, typeName)
+		}
+	}
+
+	// hooks sharing a type name are ordered by creation, see [NewHook]
+	hookDiffs := []HookDiff{}
+	seqs := []uint64{}
+	for key, h := range hooksA {
+		if other := hooksB[key]; other.listeners != h.listeners {
+			hookDiffs = append(hookDiffs, HookDiff{h.typeName, h.listeners, other.listeners})
+			seqs = append(seqs, keySeq(key))
+		}
+	}
+	for key, h := range hooksB {
+		if _, ok := hooksA[key]; !ok && h.listeners > 0 {
+			hookDiffs = append(hookDiffs, HookDiff{h.typeName, 0, h.listeners})
+			seqs = append(seqs, keySeq(key))
+		}
+	}
+
+	order := make([]int, len(hookDiffs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := hookDiffs[order[i]], hookDiffs[order[j]]
+		if a.TypeName != b.TypeName {
+			return a.TypeName < b.TypeName
+		}
+
+		return seqs[order[i]] < seqs[order[j]]
+	})
+
+	for _, i := range order {
+		result.Hooks = append(result.Hooks, hookDiffs[i])
+	}
+
+	return result
+}
+
+// hookCount is the number of listeners of a hook
+type hookCount struct {
+	typeName  string
+	listeners int
+}
+
+// structure returns the number of slots for each type name and the number of
+// listeners of each hook of this locator
+func (l *ServiceLocator) structure() (map[string]int, map[any]hookCount) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots := map[string]int{}
+	for _, key := range l.slotKeys {
+		slots[l.providers[key].typeName]++
+	}
+
+	hooks := map[any]hookCount{}
+	for key, h := range l.hooks {
+		hooks[key] = hookCount{h.typeName, len(h.listeners)}
+	}
+
+	return slots, hooks
+}
+
+// sortedKeys returns the keys of a set of strings in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
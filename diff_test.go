@@ -0,0 +1,60 @@
+package sl_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestDiff(t *testing.T) {
+	exampleHook := sl.NewHook[string]()
+	otherHook := sl.NewHook[int]()
+
+	listener := func(l *sl.ServiceLocator, s string) error { return nil }
+
+	staging := sl.New()
+	sl.Provide(staging, ConfigSlot, &Config{Foo: "staging"})
+	sl.Provide(staging, LoggerSlot, log.Default())
+	sl.ProvideHook(staging, exampleHook, listener, listener)
+
+	prod := sl.New()
+	sl.Provide(prod, ConfigSlot, &Config{Foo: "prod"})
+	sl.ProvideFunc(prod, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{}, nil
+	})
+	sl.ProvideHook(prod, exampleHook, listener)
+	sl.ProvideHook(prod, otherHook, func(l *sl.ServiceLocator, i int) error { return nil })
+
+	diff := sl.Diff(staging, prod)
+	assert.Equal(t, diff.Empty(), false)
+	assert.DeepEqual(t, diff.OnlyInA, []string{"*log.Logger"})
+	assert.DeepEqual(t, diff.OnlyInB, []string{"*sl_test.ExampleService"})
+	assert.Equal(t, diff.String(), ""+
+		"- slot *log.Logger\n"+
+		"+ slot *sl_test.ExampleService\n"+
+		"~ hook int: 0 != 1 listeners\n"+
+		"~ hook string: 2 != 1 listeners\n",
+	)
+
+	assert.Assert(t, sl.Diff(prod, prod).Empty())
+}
+
+func TestDiffHooksSameTypeName(t *testing.T) {
+	firstHook := sl.NewHook[string]()
+	secondHook := sl.NewHook[string]()
+
+	listener := func(l *sl.ServiceLocator, s string) error { return nil }
+
+	for i := 0; i < 20; i++ {
+		a := sl.New()
+		sl.ProvideHook(a, secondHook, listener, listener)
+		sl.ProvideHook(a, firstHook, listener)
+
+		assert.DeepEqual(t, sl.Diff(a, sl.New()).Hooks, []sl.HookDiff{
+			{TypeName: "string", ListenersA: 1, ListenersB: 0},
+			{TypeName: "string", ListenersA: 2, ListenersB: 0},
+		})
+	}
+}
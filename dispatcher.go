@@ -0,0 +1,69 @@
+package sl
+
+import "sync"
+
+// Dispatcher is a registry of handlers of type "T" keyed by values of type
+// "K", for example the handlers of the commands of a CLI keyed by an enum of
+// commands. It is stored in a slot of the locator, see [NewDispatcher].
+//
+// A dispatcher is safe for concurrent use and its zero value is an empty
+// dispatcher ready to use.
+type Dispatcher[K comparable, T any] struct {
+	mu       sync.Mutex
+	keys     []K
+	handlers map[K]T
+}
+
+// NewDispatcher returns the dispatcher stored in the given slot, creating and
+// providing an empty one if the slot has no provider yet. Modules can call
+// this to register their handlers without coordinating who creates the
+// dispatcher, for example
+//
+//	sl.NewDispatcher(l, CommandsSlot).Register(CommandServe, serveHandler)
+//
+// This panics with a [*SlotError] if the slot is provided but can't be
+// resolved.
+func NewDispatcher[K comparable, T any](l *ServiceLocator, key slot[*Dispatcher[K, T]]) *Dispatcher[K, T] {
+	d, err := UseOrProvide(l, key, func(*ServiceLocator) (*Dispatcher[K, T], error) {
+		return &Dispatcher[K, T]{handlers: map[K]T{}}, nil
+	})
+	if err != nil {
+		panic(&SlotError{TypeName: slotName(key), Err: err})
+	}
+
+	return d
+}
+
+// Register sets the handler for "k", replacing the previous one if any
+func (d *Dispatcher[K, T]) Register(k K, h T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.handlers == nil {
+		d.handlers = map[K]T{}
+	}
+
+	if _, ok := d.handlers[k]; !ok {
+		d.keys = append(d.keys, k)
+	}
+
+	d.handlers[k] = h
+}
+
+// Get returns the handler registered for "k", false if there is none
+func (d *Dispatcher[K, T]) Get(k K) (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.handlers[k]
+	return h, ok
+}
+
+// Keys returns the keys with a registered handler in registration order, this
+// is useful to check that every value of an enum has a handler.
+func (d *Dispatcher[K, T]) Keys() []K {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]K{}, d.keys...)
+}
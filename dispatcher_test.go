@@ -0,0 +1,65 @@
+package sl_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+type Command int
+
+const (
+	CommandServe Command = iota
+	CommandMigrate
+	CommandVersion
+)
+
+type CommandHandler func(args []string) string
+
+var CommandsSlot = sl.NewSlot[*sl.Dispatcher[Command, CommandHandler]]()
+
+func TestDispatcher(t *testing.T) {
+	l := sl.New()
+
+	sl.NewDispatcher(l, CommandsSlot).Register(CommandServe, func(args []string) string {
+		return "serve"
+	})
+	sl.NewDispatcher(l, CommandsSlot).Register(CommandMigrate, func(args []string) string {
+		return "migrate"
+	})
+
+	d := sl.MustUse(l, CommandsSlot)
+	assert.Equal(t, d, sl.NewDispatcher(l, CommandsSlot))
+
+	serve, ok := d.Get(CommandServe)
+	assert.Assert(t, ok)
+	assert.Equal(t, serve(nil), "serve")
+
+	_, ok = d.Get(CommandVersion)
+	assert.Assert(t, !ok)
+
+	d.Register(CommandServe, func(args []string) string {
+		return "serve v2"
+	})
+
+	serve, _ = d.Get(CommandServe)
+	assert.Equal(t, serve(nil), "serve v2")
+	assert.DeepEqual(t, d.Keys(), []Command{CommandServe, CommandMigrate})
+}
+
+func TestDispatcherZeroValue(t *testing.T) {
+	var d sl.Dispatcher[Command, CommandHandler]
+
+	_, ok := d.Get(CommandServe)
+	assert.Assert(t, !ok)
+
+	d.Register(CommandServe, func(args []string) string {
+		return "serve"
+	})
+
+	serve, ok := d.Get(CommandServe)
+	assert.Assert(t, ok)
+	assert.Equal(t, serve(nil), "serve")
+	assert.DeepEqual(t, d.Keys(), []Command{CommandServe})
+}
@@ -0,0 +1,80 @@
+package sl
+
+import "time"
+
+// EventKind tells which field of an [Event] are meaningful
+type EventKind int
+
+const (
+	// EventProvide is fired when a value or a lazy provider gets injected in
+	// a slot
+	EventProvide EventKind = iota
+
+	// EventConfigureStart is fired when a lazy slot starts configuring itself
+	EventConfigureStart
+
+	// EventConfigureEnd is fired when a lazy slot finished configuring
+	// itself, the event also carries the duration and the error if any
+	EventConfigureEnd
+
+	// EventHookDispatch is fired when a hook gets called
+	EventHookDispatch
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventProvide:
+		return "provide"
+	case EventConfigureStart:
+		return "configure-start"
+	case EventConfigureEnd:
+		return "configure-end"
+	case EventHookDispatch:
+		return "hook-dispatch"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that happened inside a [ServiceLocator], see
+// [ServiceLocator.Subscribe].
+type Event struct {
+	Kind EventKind
+
+	// TypeName is the type name of the slot or hook this event refers to
+	TypeName string
+
+	// Duration is set only for [EventConfigureEnd] events
+	Duration time.Duration
+
+	// Err is set only for [EventConfigureEnd] events of slots that failed to
+	// configure
+	Err error
+}
+
+// Subscribe registers a function called for every [Event] happening in this
+// locator and in its child scopes.
+//
+// Subscribers are called synchronously in registration order, so they should
+// return quickly to not slow down the resolution of services.
+func (l *ServiceLocator) Subscribe(fn func(Event)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.subscribers = append(l.subscribers, fn)
+}
+
+// emit calls all the subscribers of this locator and of its parent scopes,
+// the lock of the locator must not be held.
+func (l *ServiceLocator) emit(e Event) {
+	l.mu.Lock()
+	subscribers := []func(Event){}
+	for current := l; current != nil; current = current.parent {
+		subscribers = append(subscribers, current.subscribers...)
+	}
+	l.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(e)
+	}
+}
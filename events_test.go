@@ -0,0 +1,45 @@
+package sl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	l := sl.New()
+
+	events := []string{}
+	l.Subscribe(func(e sl.Event) {
+		events = append(events, e.Kind.String()+" "+e.TypeName)
+	})
+
+	failures := 0
+	l.Subscribe(func(e sl.Event) {
+		if e.Err != nil {
+			failures++
+		}
+	})
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return nil, errors.New("failure")
+	})
+
+	exampleHook := sl.NewHook[string]()
+	sl.ProvideHook(l, exampleHook, func(l *sl.ServiceLocator, s string) error { return nil })
+
+	sl.Invoke(l, ExampleServiceSlot)
+	sl.MustUseHook(l, exampleHook, "foo")
+
+	assert.DeepEqual(t, events, []string{
+		"provide *sl_test.Config",
+		"provide *sl_test.ExampleService",
+		"configure-start *sl_test.ExampleService",
+		"configure-end *sl_test.ExampleService",
+		"hook-dispatch string",
+	})
+	assert.Equal(t, failures, 1)
+}
@@ -0,0 +1,32 @@
+package sl
+
+// Fixture creates fresh locators wired with the same modules, this is useful
+// in table driven tests where each case needs its own locator, for example
+//
+//	fixture := sl.NewFixture(database.Module, sl.ModuleFunc(func(l *sl.ServiceLocator) {
+//		sl.Provide(l, ConfigSlot, testConfig)
+//	}))
+//
+//	for _, tc := range cases {
+//		l := fixture.New()
+//		...
+//	}
+type Fixture struct {
+	modules []Module
+}
+
+// NewFixture creates a [Fixture] installing the given modules in order
+func NewFixture(setup ...Module) *Fixture {
+	return &Fixture{modules: append([]Module{}, setup...)}
+}
+
+// New creates a new [ServiceLocator] and installs the modules of the fixture
+// in it, each call returns an independent locator.
+func (f *Fixture) New() *ServiceLocator {
+	l := New()
+	for _, m := range f.modules {
+		m.Install(l)
+	}
+
+	return l
+}
@@ -0,0 +1,40 @@
+package sl_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestFixture(t *testing.T) {
+	created := 0
+	module := sl.Define().ProvideFunc(func(l *sl.ServiceLocator) {
+		sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+			created++
+			return &ExampleService{Bar: sl.MustUse(l, ConfigSlot).Foo}, nil
+		})
+	})
+
+	fixture := sl.NewFixture(module, sl.ModuleFunc(func(l *sl.ServiceLocator) {
+		sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	}))
+
+	assert.Equal(t, sl.MustUse(fixture.New(), ExampleServiceSlot).Bar, "foo")
+
+	locators := map[string]*sl.ServiceLocator{}
+	for _, foo := range []string{"a", "b"} {
+		l := fixture.New()
+		sl.Override(l, ConfigSlot, &Config{Foo: foo})
+		assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, foo)
+
+		locators[foo] = l
+	}
+
+	for foo, l := range locators {
+		assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, foo)
+	}
+
+	assert.Equal(t, created, 3)
+	assert.Equal(t, sl.MustUse(fixture.New(), ConfigSlot).Foo, "foo")
+}
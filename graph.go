@@ -0,0 +1,95 @@
+package sl
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// graphSlot describes a slot in the output of [ServiceLocator.LogGraph]
+type graphSlot struct {
+	Type         string   `json:"type"`
+	Lazy         bool     `json:"lazy"`
+	Configured   bool     `json:"configured"`
+	Module       string   `json:"module,omitempty"`
+	Source       string   `json:"source,omitempty"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// graphHook describes a hook in the output of [ServiceLocator.LogGraph]
+type graphHook struct {
+	Type      string `json:"type"`
+	Listeners int    `json:"listeners"`
+}
+
+// graphGroup describes a group in the output of [ServiceLocator.LogGraph]
+type graphGroup struct {
+	Type    string `json:"type"`
+	Members int    `json:"members"`
+}
+
+// graph is the object written by [ServiceLocator.LogGraph]
+type graph struct {
+	Slots  []graphSlot  `json:"slots"`
+	Hooks  []graphHook  `json:"hooks"`
+	Groups []graphGroup `json:"groups"`
+}
+
+// LogGraph writes to "w" a single JSON object describing how this locator
+// (not including its parent scopes) is wired, for example to keep an audit
+// log of each deploy. The object has the following fields
+//
+//   - "slots" are the slots in registration order with their type name,
+//     whether they are lazy and configured, the module and source that
+//     provided them if known and the type names of their dependencies;
+//   - "hooks" are the hooks sorted by type name with their number of
+//     listeners;
+//   - "groups" are the groups of [ProvideMulti] sorted by type name with
+//     their number of members.
+//
+// Dependencies are only known for slots already configured, so this is most
+// useful after [ServiceLocator.Validate] or [ServiceLocator.WarmUp]. This
+// doesn't configure any slot.
+func (l *ServiceLocator) LogGraph(w io.Writer) error {
+	l.mu.Lock()
+
+	g := graph{
+		Slots:  make([]graphSlot, 0, len(l.slotKeys)),
+		Hooks:  make([]graphHook, 0, len(l.hooks)),
+		Groups: make([]graphGroup, 0, len(l.groups)),
+	}
+
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+
+		dependencies := make([]string, 0, len(s.dependencies))
+		for _, d := range s.dependencies {
+			dependencies = append(dependencies, d.typeName)
+		}
+
+		g.Slots = append(g.Slots, graphSlot{
+			Type:         s.typeName,
+			Lazy:         s.configureFunc != nil,
+			Configured:   s.configured,
+			Module:       s.module,
+			Source:       s.source,
+			Dependencies: dependencies,
+		})
+	}
+
+	for _, h := range l.hooks {
+		g.Hooks = append(g.Hooks, graphHook{Type: h.typeName, Listeners: len(h.listeners)})
+	}
+
+	for _, members := range l.groups {
+		if len(members) > 0 {
+			g.Groups = append(g.Groups, graphGroup{Type: members[0].typeName, Members: len(members)})
+		}
+	}
+	l.mu.Unlock()
+
+	sort.SliceStable(g.Hooks, func(i, j int) bool { return g.Hooks[i].Type < g.Hooks[j].Type })
+	sort.SliceStable(g.Groups, func(i, j int) bool { return g.Groups[i].Type < g.Groups[j].Type })
+
+	return json.NewEncoder(w).Encode(g)
+}
@@ -0,0 +1,74 @@
+package sl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestLogGraph(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		config, err := sl.Use(l, ConfigSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExampleService{Bar: config.Foo}, nil
+	})
+
+	exampleHook := sl.NewHook[string]()
+	sl.ProvideHook(l, exampleHook,
+		func(l *sl.ServiceLocator, s string) error { return nil },
+		func(l *sl.ServiceLocator, s string) error { return nil },
+	)
+
+	pluginsSlot := sl.NewSlot[string]()
+	sl.ProvideMulti(l, pluginsSlot, "a")
+
+	type graph struct {
+		Slots []struct {
+			Type         string
+			Lazy         bool
+			Configured   bool
+			Dependencies []string
+		}
+		Hooks []struct {
+			Type      string
+			Listeners int
+		}
+		Groups []struct {
+			Type    string
+			Members int
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NilError(t, l.LogGraph(&buf))
+
+	var g graph
+	assert.NilError(t, json.Unmarshal(buf.Bytes(), &g))
+	assert.Equal(t, len(g.Slots), 2)
+	assert.Equal(t, g.Slots[1].Lazy, true)
+	assert.Equal(t, g.Slots[1].Configured, false)
+	assert.DeepEqual(t, g.Slots[1].Dependencies, []string{})
+	assert.Equal(t, len(g.Hooks), 1)
+	assert.Equal(t, g.Hooks[0].Listeners, 2)
+	assert.Equal(t, len(g.Groups), 1)
+	assert.Equal(t, g.Groups[0].Members, 1)
+
+	sl.MustInvoke(l, ExampleServiceSlot)
+
+	buf.Reset()
+	assert.NilError(t, l.LogGraph(&buf))
+
+	g = graph{}
+	assert.NilError(t, json.Unmarshal(buf.Bytes(), &g))
+	assert.Equal(t, g.Slots[1].Configured, true)
+	assert.DeepEqual(t, g.Slots[1].Dependencies, []string{g.Slots[0].Type})
+}
@@ -0,0 +1,338 @@
+package sl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// HookResult is the outcome of a single hook listener, see [UseHookCollect]
+type HookResult struct {
+	// Index is the position of the listener in the hook
+	Index int
+
+	// Err is the error returned by the listener, nil on success
+	Err error
+}
+
+// UseHookCollect is like [UseHook] but all listeners are called even if some
+// of them fail. The returned slice has a result for each listener in order
+// and the returned error joins all the errors of the listeners. A listener
+// returning [ErrStopPropagation] counts as successful and skips the next
+// listeners, so they have no result.
+func UseHookCollect[T any](l *ServiceLocator, hookKey hook[T], value T) ([]HookResult, error) {
+	typeName, listeners, ok, err := l.hookListeners(hookKey, payloadTypeOf[T]())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf(`no injected hooks for hook of type %s`, getTypeName[T]())
+	}
+
+	l.logf(`[hook: %s] calling hook with value of type %T`, typeName, value)
+	l.emit(Event{Kind: EventHookDispatch, TypeName: typeName})
+
+	results := make([]HookResult, 0, len(listeners))
+	for i, hookFunc := range listeners {
+		err := hookFunc(l, value)
+		if errors.Is(err, ErrStopPropagation) {
+			results = append(results, HookResult{Index: i})
+			break
+		}
+
+		results = append(results, HookResult{Index: i, Err: err})
+	}
+
+	return results, joinHookResults(results)
+}
+
+// UseHookReport is the same as [UseHook] but also returns the indices of the
+// listeners that completed successfully, all of them on success or the ones
+// before the failing listener otherwise. This tells which side effects
+// happened when the dispatch stops partway. A listener returning
+// [ErrStopPropagation] counts as successful.
+func UseHookReport[T any](l *ServiceLocator, hookKey hook[T], value T) ([]int, error) {
+	typeName, listeners, ok, err := l.hookListeners(hookKey, payloadTypeOf[T]())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf(`no injected hooks for hook of type %s`, getTypeName[T]())
+	}
+
+	l.logf(`[hook: %s] calling hook with value of type %T`, typeName, value)
+	l.emit(Event{Kind: EventHookDispatch, TypeName: typeName})
+
+	ran := []int{}
+	for i, hookFunc := range listeners {
+		if err := hookFunc(l, value); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				return append(ran, i), nil
+			}
+
+			return ran, err
+		}
+
+		ran = append(ran, i)
+	}
+
+	return ran, nil
+}
+
+// UseHookConcurrent is like [UseHookCollect] but all listeners are called
+// concurrently, each in its own goroutine. Results are still ordered by
+// listener index. With [ServiceLocator.SetDeterministic] listeners are called
+// one at a time in order.
+//
+// Listeners can't stop the others as they all run at the same time, so
+// [ErrStopPropagation] has no meaning here and counts as successful.
+func UseHookConcurrent[T any](l *ServiceLocator, hookKey hook[T], value T) ([]HookResult, error) {
+	typeName, listeners, ok, err := l.hookListeners(hookKey, payloadTypeOf[T]())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf(`no injected hooks for hook of type %s`, getTypeName[T]())
+	}
+
+	l.logf(`[hook: %s] calling concurrent hook with value of type %T`, typeName, value)
+	l.emit(Event{Kind: EventHookDispatch, TypeName: typeName})
+
+	results := make([]HookResult, len(listeners))
+
+	l.mu.Lock()
+	deterministic := l.settings.deterministic
+	l.mu.Unlock()
+
+	if deterministic {
+		for i, hookFunc := range listeners {
+			results[i] = HookResult{Index: i, Err: ignoreStop(hookFunc(l, value))}
+		}
+
+		return results, joinHookResults(results)
+	}
+
+	var wg sync.WaitGroup
+	for i, hookFunc := range listeners {
+		wg.Add(1)
+		go func(i int, hookFunc func(*ServiceLocator, any) error) {
+			defer wg.Done()
+			results[i] = HookResult{Index: i, Err: ignoreStop(hookFunc(l, value))}
+		}(i, hookFunc)
+	}
+	wg.Wait()
+
+	return results, joinHookResults(results)
+}
+
+// CollectHook calls the listeners of the given hook with a pointer to a new
+// zero value of "T" and returns the value they built, stopping at the first
+// error like [UseHook]. A hook with no listeners collects the zero value. This
+// lets a service be built from the contributions of many modules, for example
+//
+//	sl.ProvideFunc(l, RouterSlot, func(l *sl.ServiceLocator) (*Router, error) {
+//		routes, err := sl.CollectHook(l, RoutesHook)
+//		if err != nil {
+//			return nil, err
+//		}
+//
+//		return NewRouter(routes), nil
+//	})
+//
+// When called from a "createFunc" the slots used by the listeners are
+// recorded as dependencies of the slot being configured, and a listener using
+// the slot being configured returns an error wrapping [ErrDependencyCycle].
+func CollectHook[T any](l *ServiceLocator, hookKey hook[*T]) (T, error) {
+	var collected T
+	if err := UseHookOptional(l, hookKey, &collected); err != nil {
+		return zero[T](), err
+	}
+
+	return collected, nil
+}
+
+// Pipe is a listener of a hook used as a pipeline, it receives the current
+// value and returns the value for the next listener. See [PipeHook].
+type Pipe[T any] func(*ServiceLocator, T) (T, error)
+
+// pipeValue is the payload dispatched by [PipeHook], listeners replace the
+// value they receive
+type pipeValue struct {
+	value any
+}
+
+// pipeValueType is the payload type of hooks provided with [ProvidePipe]
+var pipeValueType = reflect.TypeOf(&pipeValue{})
+
+// ProvidePipe is like [ProvideHook] but attaches listeners that transform the
+// value of the hook, see [PipeHook]. A hook provided with this function must
+// only be dispatched with [PipeHook].
+func ProvidePipe[T any](l *ServiceLocator, hookKey hook[T], listeners ...Pipe[T]) {
+	typeName := getTypeName[T]()
+	l.logf(`[hook: %s] injecting pipe`, typeName)
+
+	anyListeners := make([]func(*ServiceLocator, any) error, 0, len(listeners))
+	for i, listener := range listeners {
+		if listener == nil {
+			l.logf(`[hook: %s] skipped nil listener at index %d`, typeName, i)
+			continue
+		}
+
+		listener := listener
+		anyListeners = append(anyListeners, func(l *ServiceLocator, a any) error {
+			p, ok := a.(*pipeValue)
+			if !ok {
+				panic(`illegal state`)
+			}
+
+			current, _ := p.value.(T)
+			next, err := listener(l, current)
+			if err != nil {
+				return err
+			}
+
+			p.value = next
+			return nil
+		})
+	}
+
+	l.mu.Lock()
+	l.hooks[hookKey] = &hookEntry{
+		typeName:    typeName,
+		payloadType: pipeValueType,
+		listeners:   anyListeners,
+	}
+	l.mu.Unlock()
+}
+
+// PipeHook threads "initial" through the listeners of the given hook provided
+// with [ProvidePipe], each listener receives the value returned by the
+// previous one and the value returned by the last listener is returned. This
+// stops at the first error, a hook with no listeners returns "initial". A
+// listener can return [ErrStopPropagation] to skip the next listeners, in this
+// case the value returned by the previous listener is returned.
+func PipeHook[T any](l *ServiceLocator, hookKey hook[T], initial T) (T, error) {
+	typeName, listeners, ok, err := l.hookListeners(hookKey, pipeValueType)
+	if err != nil {
+		return zero[T](), err
+	}
+	if !ok {
+		return initial, nil
+	}
+
+	l.logf(`[hook: %s] calling pipe with value of type %T`, typeName, initial)
+	l.emit(Event{Kind: EventHookDispatch, TypeName: typeName})
+
+	p := &pipeValue{initial}
+	for _, hookFunc := range listeners {
+		if err := hookFunc(l, p); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				break
+			}
+
+			return zero[T](), err
+		}
+	}
+
+	v, _ := p.value.(T)
+	return v, nil
+}
+
+// OnHookListener registers a callback called after each hook listener of this
+// locator (and of child scopes created afterwards) with the type name of the
+// hook, the index of the listener, how long it took and the error it
+// returned. This is useful to find slow listeners, when no callback is
+// registered listeners are not timed at all.
+//
+// The callback is called by the dispatching goroutine, so with
+// [UseHookConcurrent] it must be safe for concurrent use. Passing nil removes
+// the callback.
+func (l *ServiceLocator) OnHookListener(fn func(hookType string, index int, dur time.Duration, err error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.onHookListener = fn
+}
+
+// timedListener wraps a listener to report its duration to "observe"
+func timedListener(typeName string, index int, listener func(*ServiceLocator, any) error, observe func(string, int, time.Duration, error)) func(*ServiceLocator, any) error {
+	return func(l *ServiceLocator, value any) error {
+		start := time.Now()
+		err := listener(l, value)
+		observe(typeName, index, time.Since(start), err)
+
+		return err
+	}
+}
+
+// ignoreStop returns nil for [ErrStopPropagation] and "err" otherwise, see
+// [UseHookConcurrent]
+func ignoreStop(err error) error {
+	if errors.Is(err, ErrStopPropagation) {
+		return nil
+	}
+
+	return err
+}
+
+// joinHookResults joins the errors of the failed listeners annotating them
+// with the listener index
+func joinHookResults(results []HookResult) error {
+	errs := []error{}
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf(`listener %d: %w`, r.Index, r.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Subscribe appends a listener to the given hook of this locator, unlike
+// [ProvideHook] this keeps the listeners already registered so hooks can be
+// used as a runtime event bus together with [Publish]. If the hook is only
+// registered in a parent scope, the listener is added to a new hook of this
+// locator extending the parent one: dispatching from this locator calls the
+// listeners of the parent scopes first and then the ones of this locator.
+//
+// This is safe to call concurrently with [Publish] and from listeners
+// themselves, a listener added during a dispatch is called starting from the
+// next one. A nil listener is ignored.
+func Subscribe[T any](l *ServiceLocator, topic hook[T], fn Hook[T]) {
+	typeName := getTypeName[T]()
+	if fn == nil {
+		l.logf(`[hook: %s] skipped nil listener`, typeName)
+		return
+	}
+
+	l.logf(`[hook: %s] subscribing listener`, typeName)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.hooks[topic]
+	if !ok {
+		entry = &hookEntry{typeName: typeName, payloadType: payloadTypeOf[T](), inherit: true}
+		l.hooks[topic] = entry
+	}
+
+	if err := entry.checkPayload(payloadTypeOf[T]()); err != nil {
+		l.logf(`[hook: %s] skipped listener: %v`, typeName, err)
+		return
+	}
+
+	entry.listeners = append(entry.listeners, toAnyListener(fn))
+	if entry.alive != nil {
+		entry.alive = append(entry.alive, nil)
+	}
+}
+
+// Publish calls all the listeners of the given hook with "event" in the order
+// they were registered, stopping at the first error like [UseHook]. Unlike
+// [UseHook] publishing to a hook without listeners is a no-op, this can be
+// called any number of times and concurrently from multiple goroutines.
+func Publish[T any](l *ServiceLocator, topic hook[T], event T) error {
+	return UseHookOptional(l, topic, event)
+}
@@ -0,0 +1,445 @@
+package sl_test
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestUseHookCollect(t *testing.T) {
+	exampleHook := sl.NewHook[string]()
+
+	errFailure := errors.New("failure")
+
+	var called atomic.Int32
+	ok := func(l *sl.ServiceLocator, s string) error {
+		called.Add(1)
+		return nil
+	}
+	fail := func(l *sl.ServiceLocator, s string) error {
+		return errFailure
+	}
+
+	l := sl.New()
+	sl.ProvideHook(l, exampleHook, ok, fail, ok, fail)
+
+	assertResults := func(results []sl.HookResult, err error) {
+		assert.Assert(t, errors.Is(err, errFailure))
+		assert.ErrorContains(t, err, "listener 1: failure")
+		assert.ErrorContains(t, err, "listener 3: failure")
+
+		assert.Equal(t, len(results), 4)
+		for i, r := range results {
+			assert.Equal(t, r.Index, i)
+			assert.Equal(t, r.Err != nil, i%2 == 1)
+		}
+	}
+
+	assertResults(sl.UseHookCollect(l, exampleHook, "foo"))
+	assert.Equal(t, called.Load(), int32(2))
+
+	assertResults(sl.UseHookConcurrent(l, exampleHook, "foo"))
+	assert.Equal(t, called.Load(), int32(4))
+
+	stop := func(l *sl.ServiceLocator, s string) error {
+		return sl.ErrStopPropagation
+	}
+	sl.ProvideHook(l, exampleHook, ok, stop, fail)
+
+	results, err := sl.UseHookCollect(l, exampleHook, "foo")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results, []sl.HookResult{{Index: 0}, {Index: 1}})
+
+	results, err = sl.UseHookConcurrent(l, exampleHook, "foo")
+	assert.ErrorContains(t, err, "listener 2: failure")
+	assert.Assert(t, !strings.Contains(err.Error(), "stop"))
+	assert.Equal(t, len(results), 3)
+	assert.NilError(t, results[1].Err)
+	assert.Equal(t, results[2].Err, errFailure)
+}
+
+func TestPublish(t *testing.T) {
+	topic := sl.NewHook[int]()
+
+	l := sl.New()
+
+	assert.NilError(t, sl.Publish(l, topic, 0))
+
+	var total atomic.Int64
+	var subscribed atomic.Bool
+	sl.Subscribe(l, topic, func(l *sl.ServiceLocator, n int) error {
+		total.Add(int64(n))
+
+		if subscribed.CompareAndSwap(false, true) {
+			sl.Subscribe(l, topic, func(l *sl.ServiceLocator, n int) error {
+				total.Add(int64(100 * n))
+				return nil
+			})
+		}
+
+		return nil
+	})
+
+	assert.NilError(t, sl.Publish(l, topic, 1))
+	assert.Equal(t, total.Load(), int64(1))
+
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			assert.Check(t, sl.Publish(l, topic, 1))
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	assert.Equal(t, total.Load(), int64(1+10*101))
+}
+
+func TestPublishScope(t *testing.T) {
+	topic := sl.NewHook[string]()
+
+	l := sl.New()
+	child := l.Scope()
+
+	received := []string{}
+	listener := func(name string) sl.Hook[string] {
+		return func(l *sl.ServiceLocator, event string) error {
+			received = append(received, name+": "+event)
+			return nil
+		}
+	}
+
+	sl.Subscribe(child, topic, listener("child"))
+	sl.Subscribe(l, topic, listener("root"))
+
+	assert.NilError(t, sl.Publish(child, topic, "a"))
+	assert.NilError(t, sl.Publish(l, topic, "b"))
+	assert.DeepEqual(t, received, []string{"root: a", "child: a", "root: b"})
+
+	// a hook provided in the child still replaces the parent one
+	received = []string{}
+	other := child.Scope()
+	sl.ProvideHook(other, topic, listener("other"))
+
+	assert.NilError(t, sl.Publish(other, topic, "c"))
+	assert.DeepEqual(t, received, []string{"other: c"})
+}
+
+func TestUseHookReport(t *testing.T) {
+	exampleHook := sl.NewHook[string]()
+
+	errFailure := errors.New("failure")
+	ok := func(l *sl.ServiceLocator, s string) error { return nil }
+	fail := func(l *sl.ServiceLocator, s string) error { return errFailure }
+
+	l := sl.New()
+
+	sl.ProvideHook(l, exampleHook, ok, ok, ok)
+	ran, err := sl.UseHookReport(l, exampleHook, "foo")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ran, []int{0, 1, 2})
+
+	sl.ProvideHook(l, exampleHook, ok, ok, fail, ok)
+	ran, err = sl.UseHookReport(l, exampleHook, "foo")
+	assert.Equal(t, err, errFailure)
+	assert.DeepEqual(t, ran, []int{0, 1})
+}
+
+func TestOnHookListener(t *testing.T) {
+	exampleHook := sl.NewHook[string]()
+
+	errFailure := errors.New("failure")
+
+	l := sl.New()
+	sl.ProvideHook(l, exampleHook,
+		func(l *sl.ServiceLocator, s string) error { return nil },
+		func(l *sl.ServiceLocator, s string) error { return errFailure },
+	)
+
+	indices := []int{}
+	errs := []error{}
+	l.OnHookListener(func(hookType string, index int, dur time.Duration, err error) {
+		assert.Equal(t, hookType, "string")
+		assert.Assert(t, dur >= 0)
+
+		indices = append(indices, index)
+		errs = append(errs, err)
+	})
+
+	err := sl.UseHook(l, exampleHook, "foo")
+	assert.Assert(t, errors.Is(err, errFailure))
+	assert.DeepEqual(t, indices, []int{0, 1})
+	assert.Assert(t, errs[0] == nil)
+	assert.Assert(t, errors.Is(errs[1], errFailure))
+
+	l.OnHookListener(nil)
+	sl.UseHookCollect(l, exampleHook, "foo")
+	assert.Equal(t, len(indices), 2)
+}
+
+func TestCollectHook(t *testing.T) {
+	routesHook := sl.NewHook[*[]string]()
+	routerSlot := sl.NewSlot[[]string]()
+
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "/foo"})
+
+	sl.ProvideHook(l, routesHook,
+		func(l *sl.ServiceLocator, routes *[]string) error {
+			*routes = append(*routes, "/")
+			return nil
+		},
+		func(l *sl.ServiceLocator, routes *[]string) error {
+			*routes = append(*routes, sl.MustUse(l, ConfigSlot).Foo)
+			return nil
+		},
+	)
+
+	sl.ProvideFunc(l, routerSlot, func(l *sl.ServiceLocator) ([]string, error) {
+		return sl.CollectHook(l, routesHook)
+	})
+
+	assert.DeepEqual(t, sl.MustUse(l, routerSlot), []string{"/", "/foo"})
+	assert.DeepEqual(t, sl.ResetCascade(l, ConfigSlot), []string{"[]string"})
+
+	sl.Subscribe(l, routesHook, func(l *sl.ServiceLocator, routes *[]string) error {
+		_, err := sl.Use(l, routerSlot)
+		return err
+	})
+
+	sl.Reset(l, routerSlot)
+	_, err := sl.Use(l, routerSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrDependencyCycle))
+	assert.ErrorContains(t, err, "dependency cycle: []string -> []string")
+}
+
+func TestDependencyCycle(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		_, err := sl.Use(l, ExampleServiceSlot)
+		return &Config{}, err
+	})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		_, err := sl.Use(l, ConfigSlot)
+		return &ExampleService{}, err
+	})
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrDependencyCycle))
+	assert.ErrorContains(t, err, "*sl_test.Config -> *sl_test.ExampleService -> *sl_test.Config")
+}
+
+func TestInterfaceHook(t *testing.T) {
+	greetHook := sl.NewHook[Greeter]()
+
+	l := sl.New()
+
+	greetings := []string{}
+	italians := 0
+	sl.ProvideHook(l, greetHook,
+		func(l *sl.ServiceLocator, g Greeter) error {
+			if g != nil {
+				greetings = append(greetings, g.Greet())
+			}
+			return nil
+		},
+		func(l *sl.ServiceLocator, g Greeter) error {
+			if _, ok := g.(*ItalianGreeter); ok {
+				italians++
+			}
+			return nil
+		},
+	)
+
+	assert.NilError(t, sl.UseHook[Greeter](l, greetHook, &EnglishGreeter{Name: "World"}))
+	assert.NilError(t, sl.UseHook[Greeter](l, greetHook, &ItalianGreeter{Name: "Mondo"}))
+	assert.NilError(t, sl.UseHook(l, greetHook, nil))
+
+	assert.DeepEqual(t, greetings, []string{"Hello World", "Ciao Mondo"})
+	assert.Equal(t, italians, 1)
+}
+
+func TestErrStopPropagation(t *testing.T) {
+	exampleHook := sl.NewHook[string]()
+
+	l := sl.New()
+
+	called := []int{}
+	listener := func(i int, err error) sl.Hook[string] {
+		return func(l *sl.ServiceLocator, s string) error {
+			called = append(called, i)
+			return err
+		}
+	}
+
+	sl.ProvideHook(l, exampleHook,
+		listener(0, nil),
+		listener(1, sl.ErrStopPropagation),
+		listener(2, nil),
+	)
+
+	assert.NilError(t, sl.UseHook(l, exampleHook, "foo"))
+	assert.DeepEqual(t, called, []int{0, 1})
+
+	ran, err := sl.UseHookReport(l, exampleHook, "foo")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ran, []int{0, 1})
+}
+
+func TestPipeHook(t *testing.T) {
+	slugHook := sl.NewHook[string]()
+
+	l := sl.New()
+
+	slug, err := sl.PipeHook(l, slugHook, "Hello World")
+	assert.NilError(t, err)
+	assert.Equal(t, slug, "Hello World")
+
+	sl.ProvidePipe(l, slugHook,
+		func(l *sl.ServiceLocator, s string) (string, error) {
+			return strings.ToLower(s), nil
+		},
+		func(l *sl.ServiceLocator, s string) (string, error) {
+			return strings.ReplaceAll(s, " ", "-"), nil
+		},
+		func(l *sl.ServiceLocator, s string) (string, error) {
+			return "/" + s, nil
+		},
+	)
+
+	slug, err = sl.PipeHook(l, slugHook, "Hello World")
+	assert.NilError(t, err)
+	assert.Equal(t, slug, "/hello-world")
+
+	errInvalid := errors.New("invalid")
+	sl.ProvidePipe(l, slugHook, func(l *sl.ServiceLocator, s string) (string, error) {
+		return "", errInvalid
+	})
+
+	_, err = sl.PipeHook(l, slugHook, "Hello World")
+	assert.Assert(t, errors.Is(err, errInvalid))
+
+	// stopping keeps the value of the previous listener
+	sl.ProvidePipe(l, slugHook,
+		func(l *sl.ServiceLocator, s string) (string, error) {
+			return strings.ToLower(s), nil
+		},
+		func(l *sl.ServiceLocator, s string) (string, error) {
+			return "", sl.ErrStopPropagation
+		},
+		func(l *sl.ServiceLocator, s string) (string, error) {
+			return "/" + s, nil
+		},
+	)
+
+	slug, err = sl.PipeHook(l, slugHook, "Hello World")
+	assert.NilError(t, err)
+	assert.Equal(t, slug, "hello world")
+}
+
+func TestNilHookListener(t *testing.T) {
+	exampleHook := sl.NewHook[string]()
+
+	l := sl.New()
+
+	called := 0
+	listener := func(l *sl.ServiceLocator, s string) error {
+		called++
+		return nil
+	}
+
+	sl.ProvideHook(l, exampleHook, listener, nil, listener)
+	sl.Subscribe(l, exampleHook, nil)
+
+	assert.NilError(t, sl.UseHook(l, exampleHook, "foo"))
+	assert.Equal(t, called, 2)
+}
+
+func TestHookPayloadMismatch(t *testing.T) {
+	slugHook := sl.NewHook[string]()
+	eventHook := sl.NewHook[string]()
+
+	l := sl.New()
+
+	sl.ProvidePipe(l, slugHook, func(l *sl.ServiceLocator, s string) (string, error) {
+		return strings.ToLower(s), nil
+	})
+
+	err := sl.UseHook(l, slugHook, "Hello World")
+	assert.Error(t, err, "hook of type string is a pipe and must be dispatched with PipeHook")
+
+	_, err = sl.UseHookCollect(l, slugHook, "Hello World")
+	assert.ErrorContains(t, err, "is a pipe")
+	assert.ErrorContains(t, sl.Publish(l, slugHook, "Hello World"), "is a pipe")
+
+	called := 0
+	sl.Subscribe(l, slugHook, func(l *sl.ServiceLocator, s string) error {
+		called++
+		return nil
+	})
+
+	slug, err := sl.PipeHook(l, slugHook, "Hello World")
+	assert.NilError(t, err)
+	assert.Equal(t, slug, "hello world")
+	assert.Equal(t, called, 0)
+
+	sl.Subscribe(l, eventHook, func(l *sl.ServiceLocator, s string) error {
+		called++
+		return nil
+	})
+
+	_, err = sl.PipeHook(l, eventHook, "Hello World")
+	assert.Error(t, err, "hook of type string is not a pipe and can't be dispatched with PipeHook")
+	assert.Equal(t, called, 0)
+}
+
+func TestHookMutationDuringDispatch(t *testing.T) {
+	topic := sl.NewHook[int]()
+
+	l := sl.New()
+
+	var calls atomic.Int64
+	listener := func(l *sl.ServiceLocator, n int) error {
+		calls.Add(1)
+
+		sl.Subscribe(l, topic, func(l *sl.ServiceLocator, n int) error {
+			calls.Add(1)
+			return nil
+		})
+
+		return nil
+	}
+
+	sl.ProvideHook(l, topic, listener)
+
+	assert.NilError(t, sl.UseHook(l, topic, 1))
+	assert.Equal(t, calls.Load(), int64(1))
+
+	assert.NilError(t, sl.UseHookOptional(l, topic, 1))
+	assert.Equal(t, calls.Load(), int64(1+2))
+
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+
+			if i%2 == 0 {
+				sl.ProvideHook(l, topic, listener)
+				return
+			}
+
+			assert.Check(t, sl.Publish(l, topic, 1))
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+}
@@ -0,0 +1,173 @@
+package sl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProvideKeyed provides "value" as the instance of the given slot for "key",
+// for example one database connection for each tenant. Each key holds a
+// separate instance that can be resolved with [UseKeyed], or all at once with
+// [UseKeyedAll]. Providing the same key again replaces its instance.
+//
+// Keyed instances are separate from the value of the slot itself, so [Use]
+// doesn't see them. The keyed instances of a slot provided in a child scope
+// shadow the ones of its parent.
+func ProvideKeyed[T any, K comparable](l *ServiceLocator, slotKey slot[T], key K, value T) {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] provided instance of type %T for key %v`, typeName, value, key)
+
+	l.setKeyed(slotKey, key, &slotEntry{
+		typeName:   typeName,
+		configured: true,
+		value:      value,
+	})
+}
+
+// ProvideKeyedFunc is like [ProvideKeyed] but the instance is created lazily
+// like for [ProvideFunc], the first time it gets used.
+func ProvideKeyedFunc[T any, K comparable](l *ServiceLocator, slotKey slot[T], key K, createFunc func(*ServiceLocator) (T, error)) {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] inject lazy provider for key %v`, typeName, key)
+
+	l.setKeyed(slotKey, key, &slotEntry{
+		typeName:      typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+	})
+}
+
+// keyedInstances are the keyed instances of a slot
+type keyedInstances struct {
+	// keys are the keys of "entries" in registration order
+	keys    []any
+	entries map[any]*slotEntry
+}
+
+// setKeyed sets the instance of the given slot key for "key"
+func (l *ServiceLocator) setKeyed(slotKey, key any, entry *slotEntry) {
+	l.mu.Lock()
+	if l.sealed {
+		l.mu.Unlock()
+		l.logf(`[slot: %s] cannot provide in a sealed locator, ignored`, entry.typeName)
+		return
+	}
+	if l.keyed == nil {
+		l.keyed = map[any]*keyedInstances{}
+	}
+
+	instances, ok := l.keyed[slotKey]
+	if !ok {
+		instances = &keyedInstances{entries: map[any]*slotEntry{}}
+		l.keyed[slotKey] = instances
+	}
+
+	if _, ok := instances.entries[key]; !ok {
+		instances.keys = append(instances.keys, key)
+	}
+	instances.entries[key] = entry
+	l.mu.Unlock()
+
+	l.emit(Event{Kind: EventProvide, TypeName: entry.typeName})
+}
+
+// lookupKeyed searches the keyed instances of "slotKey" in this locator and
+// then in its parent scopes, if "keys" is not empty only the instances for
+// those keys are returned (otherwise all of them in registration order). This
+// also returns the locator owning the instances. The lock of the locator must
+// be held.
+func (l *ServiceLocator) lookupKeyed(slotKey any, keys ...any) ([]any, []*slotEntry, *ServiceLocator, bool) {
+	for current := l; current != nil; current = current.parent {
+		instances, ok := current.keyed[slotKey]
+		if !ok {
+			continue
+		}
+
+		if len(keys) == 0 {
+			keys = instances.keys
+		}
+
+		found := []any{}
+		entries := []*slotEntry{}
+		for _, key := range keys {
+			if entry, ok := instances.entries[key]; ok {
+				found = append(found, key)
+				entries = append(entries, entry)
+			}
+		}
+
+		return found, entries, current, true
+	}
+
+	return nil, nil, nil, false
+}
+
+// UseKeyed resolves the instance of the given slot provided for "key" with
+// [ProvideKeyed] or [ProvideKeyedFunc], configuring it if lazy.
+func UseKeyed[T any, K comparable](l *ServiceLocator, slotKey slot[T], key K) (T, error) {
+	typeName := slotName(slotKey)
+
+	_, values, errs, ok := useMembers[T](l, `keyed slot `+typeName, func() ([]*slotEntry, *ServiceLocator, bool) {
+		_, entries, owner, ok := l.lookupKeyed(slotKey, key)
+		return entries, owner, ok
+	})
+	if !ok {
+		if len(errs) > 0 {
+			return zero[T](), errs[0]
+		}
+
+		return zero[T](), fmt.Errorf(`%w for key %v of type %s`, ErrSlotNotFound, key, typeName)
+	}
+	if errs[0] != nil {
+		return zero[T](), fmt.Errorf(`key %v: %w`, key, errs[0])
+	}
+
+	return values[0], nil
+}
+
+// UseKeyedAll resolves all the keyed instances of the given slot provided
+// with [ProvideKeyed] or [ProvideKeyedFunc], configuring the lazy ones in
+// registration order. This returns an error if the slot has no keyed
+// instances, if any of them fails to configure or if a key is not a "K".
+func UseKeyedAll[T any, K comparable](l *ServiceLocator, slotKey slot[T]) (map[K]T, error) {
+	typeName := slotName(slotKey)
+
+	var keys []any
+	_, values, errs, ok := useMembers[T](l, `keyed slot `+typeName, func() ([]*slotEntry, *ServiceLocator, bool) {
+		var entries []*slotEntry
+		var owner *ServiceLocator
+		var ok bool
+		keys, entries, owner, ok = l.lookupKeyed(slotKey)
+		return entries, owner, ok
+	})
+	if !ok {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+
+		return nil, fmt.Errorf(`%w for keyed slot of type %s`, ErrSlotNotFound, typeName)
+	}
+
+	instances := make(map[K]T, len(keys))
+	keyErrs := []error{}
+	for i, key := range keys {
+		if errs[i] != nil {
+			keyErrs = append(keyErrs, fmt.Errorf(`key %v: %w`, key, errs[i]))
+			continue
+		}
+
+		k, ok := key.(K)
+		if !ok {
+			keyErrs = append(keyErrs, fmt.Errorf(`key %v of type %T is not a %s`, key, key, getTypeName[K]()))
+			continue
+		}
+
+		instances[k] = values[i]
+	}
+	if len(keyErrs) > 0 {
+		return nil, errors.Join(keyErrs...)
+	}
+
+	return instances, nil
+}
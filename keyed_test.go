@@ -0,0 +1,75 @@
+package sl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestUseKeyedAll(t *testing.T) {
+	dbSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	_, err := sl.UseKeyedAll[string, string](l, dbSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	created := 0
+	sl.ProvideKeyed(l, dbSlot, "acme", "db-acme")
+	sl.ProvideKeyedFunc(l, dbSlot, "globex", func(l *sl.ServiceLocator) (string, error) {
+		created++
+		return "db-globex-" + sl.MustUse(l, ConfigSlot).Foo, nil
+	})
+	sl.ProvideKeyed(l, dbSlot, "initech", "db-initech")
+
+	dbs, err := sl.UseKeyedAll[string, string](l, dbSlot)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, dbs, map[string]string{
+		"acme":    "db-acme",
+		"globex":  "db-globex-foo",
+		"initech": "db-initech",
+	})
+
+	sl.UseKeyedAll[string, string](l, dbSlot)
+	assert.Equal(t, created, 1)
+
+	db, err := sl.UseKeyed(l, dbSlot, "globex")
+	assert.NilError(t, err)
+	assert.Equal(t, db, "db-globex-foo")
+
+	_, err = sl.UseKeyed(l, dbSlot, "umbrella")
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	_, err = sl.Use(l, dbSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	_, err = sl.UseKeyedAll[string, int](l, dbSlot)
+	assert.ErrorContains(t, err, "key acme of type string is not a int")
+}
+
+func TestUseKeyedAllError(t *testing.T) {
+	dbSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	errBroken := errors.New("broken")
+	sl.ProvideKeyed(l, dbSlot, 1, "db-1")
+	sl.ProvideKeyedFunc(l, dbSlot, 2, func(l *sl.ServiceLocator) (string, error) {
+		return "", errBroken
+	})
+
+	_, err := sl.UseKeyedAll[string, int](l, dbSlot)
+	assert.Assert(t, errors.Is(err, errBroken))
+	assert.ErrorContains(t, err, "key 2: configuring string: broken")
+
+	child := l.Scope()
+	sl.ProvideKeyed(child, dbSlot, 3, "db-3")
+
+	dbs, err := sl.UseKeyedAll[string, int](child, dbSlot)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, dbs, map[int]string{3: "db-3"})
+}
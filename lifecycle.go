@@ -0,0 +1,471 @@
+package sl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProvideFuncCleanup is like [ProvideFunc] but also registers a cleanup
+// function called with the created value by [ServiceLocator.Close]. The
+// cleanup is not called if the value was never created or is a nil interface
+// value.
+func ProvideFuncCleanup[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error), cleanup func(T) error) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject lazy provider with cleanup`, typeName)
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:      typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+		cleanupFunc:   typedCleanup(cleanup),
+	})
+}
+
+// typedCleanup converts a cleanup function to the internal untyped version,
+// the cleanup is skipped for nil interface values as there is nothing to
+// clean up.
+func typedCleanup[T any](cleanup func(T) error) func(any) error {
+	return func(v any) error {
+		if v == nil {
+			return nil
+		}
+
+		t, err := assertSlotValue[T](v)
+		if err != nil {
+			return err
+		}
+
+		return cleanup(t)
+	}
+}
+
+// ProvideWithCleanup is like [Provide] but also registers a cleanup function
+// called with "value" by [ServiceLocator.Close], unless it is a nil interface
+// value.
+//
+// An explicit cleanup replaces the one added by
+// [ServiceLocator.SetAutoCloser], and providing the same value again for the
+// same slot (with or without a cleanup) doesn't register a second teardown, so
+// the value is cleaned up once.
+func ProvideWithCleanup[T any](l *ServiceLocator, slotKey slot[T], value T, cleanup func(T) error) T {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] provided value of type %T with cleanup`, typeName, value)
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:    typeName,
+		configured:  true,
+		value:       value,
+		cleanupFunc: typedCleanup(cleanup),
+	})
+
+	return value
+}
+
+// ProvideFuncWithShutdownPriority is like [ProvideFunc] but if the created
+// value implements [io.Closer] it gets closed by [ServiceLocator.Close]
+// according to "priority": slots with a higher priority are closed first and
+// slots with the same priority (the default for other slots is zero) are
+// closed in reverse configuration order. This is useful when the teardown
+// order doesn't follow the dependencies between services, for example to flush
+// a buffer before closing its backing store.
+//
+// The priority is not used by [ServiceLocator.CloseConcurrent].
+func ProvideFuncWithShutdownPriority[T any](l *ServiceLocator, slotKey slot[T], priority int, createFunc func(*ServiceLocator) (T, error)) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject lazy provider with shutdown priority %d`, typeName, priority)
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:         typeName,
+		configureFunc:    func(l *ServiceLocator) (any, error) { return createFunc(l) },
+		cleanupFunc:      closeValue,
+		shutdownPriority: priority,
+	})
+}
+
+// ProvideFuncFlushBefore is like [ProvideFunc] but if the created value
+// implements [io.Closer] it gets closed by [ServiceLocator.Close] before the
+// values of the slots in "before", for example to flush a metrics buffer
+// before closing the network client it writes to. These constraints take
+// precedence over shutdown priorities and the reverse configuration order,
+// which are used for the slots not constrained.
+//
+// Contradictory constraints (like two slots each flushing before the other)
+// are reported as an error by [ServiceLocator.Close], the slots involved are
+// closed last. The constraints are not used by
+// [ServiceLocator.CloseConcurrent].
+func ProvideFuncFlushBefore[T any](l *ServiceLocator, slotKey slot[T], before []any, createFunc func(*ServiceLocator) (T, error)) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject lazy provider flushing before %d slots`, typeName, len(before))
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:      typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+		cleanupFunc:   closeValue,
+		flushBefore:   append([]any{}, before...),
+	})
+}
+
+// RegisterCleanup adds a cleanup not tied to any slot to this locator, for
+// example to remove a temporary directory created while bootstrapping. The
+// cleanup is called by [ServiceLocator.Close] together with the cleanups of
+// the slots, in reverse registration order. The "name" is used in logs and
+// errors in place of the type name of a slot.
+func (l *ServiceLocator) RegisterCleanup(name string, fn func() error) {
+	l.logf(`[cleanup: %s] registered`, name)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.configureOrder = append(l.configureOrder, &slotEntry{
+		typeName:    name,
+		configured:  true,
+		cleanupFunc: func(any) error { return fn() },
+	})
+}
+
+// autoCloseFunc returns the cleanup function registered by [Provide] and
+// [ProvideFunc] when [ServiceLocator.SetAutoCloser] is enabled, nil otherwise.
+func (l *ServiceLocator) autoCloseFunc() func(any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.settings.autoCloser {
+		return nil
+	}
+
+	return closeValue
+}
+
+// closeValue closes "v" if it implements [io.Closer]
+func closeValue(v any) error {
+	if closer, ok := v.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// cleanupKey identifies the value of a slot, see [cleanupSet]
+type cleanupKey struct {
+	slotKey any
+	value   any
+}
+
+// cleanupSet tracks the values already cleaned up for each slot, a value
+// provided many times for the same slot (for example first with the
+// auto-closer and then with an explicit cleanup) is cleaned up only once.
+type cleanupSet map[cleanupKey]bool
+
+// add returns false if the value of "s" was already added for its slot,
+// entries not tied to a slot or with values that can't be compared are always
+// added.
+func (c cleanupSet) add(s *slotEntry, value any) bool {
+	if s.slotKey == nil || value == nil || !reflect.ValueOf(value).Comparable() {
+		return true
+	}
+
+	key := cleanupKey{s.slotKey, value}
+	if c[key] {
+		return false
+	}
+
+	c[key] = true
+	return true
+}
+
+// cleanup calls the cleanup function of this slot entry if any and wraps its
+// error with the slot type name
+func (s *slotEntry) cleanup(l *ServiceLocator, value any) error {
+	if s.cleanupFunc == nil {
+		return nil
+	}
+
+	l.logf(`[slot: %s] cleanup`, s.typeName)
+
+	if err := s.cleanupFunc(value); err != nil {
+		return fmt.Errorf(`closing %s: %w`, s.typeName, err)
+	}
+
+	return nil
+}
+
+// Close calls the cleanup functions of the configured slots of this locator
+// in reverse configuration order, so services are closed before their
+// dependencies, unless a different order is requested with
+// [ProvideFuncWithShutdownPriority]. All cleanups are called even if some
+// fail, the returned error joins all their errors. A value provided many times
+// for the same slot is cleaned up once, by its last registration.
+//
+// Child scopes are not closed by their parent, each scope should be closed on
+// its own.
+//
+// Closing a locator more than once (for example both from a defer and from a
+// signal handler) calls the cleanups only the first time, the next calls
+// return the same error. The same holds for [ServiceLocator.CloseConcurrent].
+func (l *ServiceLocator) Close() error {
+	return l.closeOnce(func() error { return l.close() })
+}
+
+// closeOnce calls "close" the first time this locator gets closed and returns
+// its error, the next calls return the same error.
+func (l *ServiceLocator) closeOnce(close func() error) error {
+	first := false
+	l.closed.Do(func() {
+		first = true
+		l.closeErr = close()
+	})
+
+	if !first {
+		l.logf(`already closed`)
+	}
+
+	return l.closeErr
+}
+
+// close implements [ServiceLocator.Close]
+func (l *ServiceLocator) close() error {
+	l.mu.Lock()
+	entries := l.configuredEntries()
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].shutdownPriority > entries[j].shutdownPriority
+	})
+
+	errs := []error{}
+
+	entries, err := flushOrder(entries)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	cleaned := cleanupSet{}
+	values := make([]any, len(entries))
+	for i, s := range entries {
+		values[i] = s.value
+		if s.cleanupFunc != nil && !cleaned.add(s, s.value) {
+			entries[i] = nil
+		}
+	}
+	l.mu.Unlock()
+
+	for i, s := range entries {
+		if s == nil {
+			continue
+		}
+
+		if err := s.cleanup(l, values[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// flushOrder moves each slot before the slots it must be flushed before (see
+// [ProvideFuncFlushBefore]) and otherwise keeps the order of "entries". If
+// the constraints are contradictory the slots involved are put last in their
+// order and an error is returned.
+func flushOrder(entries []*slotEntry) ([]*slotEntry, error) {
+	byKey := map[any]*slotEntry{}
+	for _, s := range entries {
+		if s.slotKey != nil {
+			byKey[s.slotKey] = s
+		}
+	}
+
+	pending := map[*slotEntry]int{}
+	for _, s := range entries {
+		for _, key := range s.flushBefore {
+			if after, ok := byKey[key]; ok && after != s {
+				pending[after]++
+			}
+		}
+	}
+
+	if len(pending) == 0 {
+		return entries, nil
+	}
+
+	ordered := make([]*slotEntry, 0, len(entries))
+	done := map[*slotEntry]bool{}
+	for len(ordered) < len(entries) {
+		next := -1
+		for i, s := range entries {
+			if !done[s] && pending[s] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+
+		s := entries[next]
+		done[s] = true
+		ordered = append(ordered, s)
+
+		for _, key := range s.flushBefore {
+			if after, ok := byKey[key]; ok && after != s {
+				pending[after]--
+			}
+		}
+	}
+
+	if len(ordered) == len(entries) {
+		return ordered, nil
+	}
+
+	names := []string{}
+	for _, s := range entries {
+		if !done[s] {
+			names = append(names, s.typeName)
+			ordered = append(ordered, s)
+		}
+	}
+
+	return ordered, fmt.Errorf(`contradictory flush constraints between %s`, strings.Join(names, `, `))
+}
+
+// CloseConcurrent is like [ServiceLocator.Close] but groups the configured
+// slots in layers using the recorded dependencies: the first layer has the
+// slots no other slot depends on, the next one the slots used only by the
+// previous layers and so on. The cleanups of each layer are called
+// concurrently and the next layer is closed only after the previous one is
+// done.
+//
+// If "ctx" is done before all the layers are closed this returns an error
+// wrapping the context error without waiting for the remaining cleanups.
+//
+// With [ServiceLocator.SetDeterministic] the cleanups of each layer are called
+// one at a time in configuration order.
+func (l *ServiceLocator) CloseConcurrent(ctx context.Context) error {
+	return l.closeOnce(func() error { return l.closeConcurrent(ctx) })
+}
+
+// closeConcurrent implements [ServiceLocator.CloseConcurrent]
+func (l *ServiceLocator) closeConcurrent(ctx context.Context) error {
+	l.mu.Lock()
+	layers := l.closeLayers()
+	cleaned := cleanupSet{}
+	values := map[*slotEntry]any{}
+	for i, layer := range layers {
+		unique := []*slotEntry{}
+		for _, s := range layer {
+			if s.cleanupFunc != nil && !cleaned.add(s, s.value) {
+				continue
+			}
+
+			unique = append(unique, s)
+			values[s] = s.value
+		}
+		layers[i] = unique
+	}
+	deterministic := l.settings.deterministic
+	l.mu.Unlock()
+
+	var mu sync.Mutex
+	errs := []error{}
+
+	for _, layer := range layers {
+		// each batch is closed sequentially by its own goroutine
+		batches := [][]*slotEntry{layer}
+		if !deterministic {
+			batches = make([][]*slotEntry, len(layer))
+			for i, s := range layer {
+				batches[i] = []*slotEntry{s}
+			}
+		}
+
+		var wg sync.WaitGroup
+		for _, batch := range batches {
+			wg.Add(1)
+			go func(batch []*slotEntry) {
+				defer wg.Done()
+				for _, s := range batch {
+					if err := s.cleanup(l, values[s]); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+					}
+				}
+			}(batch)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			mu.Lock()
+			defer mu.Unlock()
+
+			return errors.Join(append(errs, fmt.Errorf(`shutdown timed out: %w`, ctx.Err()))...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// closeLayers groups the configured slots of this locator in layers such that
+// each slot comes after all the slots depending on it, slots in the same layer
+// are in configuration order. The lock of the locator must be held.
+func (l *ServiceLocator) closeLayers() [][]*slotEntry {
+	entries := l.configuredEntries()
+
+	configured := map[*slotEntry]bool{}
+	for _, s := range entries {
+		configured[s] = true
+	}
+
+	depth := map[*slotEntry]int{}
+
+	var depthOf func(s *slotEntry) int
+	depthOf = func(s *slotEntry) int {
+		if d, ok := depth[s]; ok {
+			return d
+		}
+
+		// mark as visited to stop on (invalid) cycles
+		depth[s] = 0
+
+		d := 0
+		for _, dependent := range s.dependents {
+			if configured[dependent] && depthOf(dependent)+1 > d {
+				d = depthOf(dependent) + 1
+			}
+		}
+
+		depth[s] = d
+		return d
+	}
+
+	maxDepth := 0
+	for _, s := range entries {
+		if depthOf(s) > maxDepth {
+			maxDepth = depthOf(s)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	layers := make([][]*slotEntry, maxDepth+1)
+	for _, s := range entries {
+		layers[depth[s]] = append(layers[depth[s]], s)
+	}
+
+	return layers
+}
@@ -0,0 +1,349 @@
+package sl_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+// closeRecorder records the names of the closed services
+type closeRecorder struct {
+	mu     sync.Mutex
+	closed []string
+}
+
+func (r *closeRecorder) cleanup(name string) func(string) error {
+	return func(string) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.closed = append(r.closed, name)
+		return nil
+	}
+}
+
+// provideGraph provides a small graph of services where "app" depends on
+// "cache" and "db", and "cache" depends on "db"
+func provideGraph(l *sl.ServiceLocator, r *closeRecorder) {
+	db := sl.NewSlot[string]()
+	cache := sl.NewSlot[string]()
+	app := sl.NewSlot[string]()
+
+	sl.ProvideFuncCleanup(l, db, func(l *sl.ServiceLocator) (string, error) {
+		return "db", nil
+	}, r.cleanup("db"))
+	sl.ProvideFuncCleanup(l, cache, func(l *sl.ServiceLocator) (string, error) {
+		return "cache", sl.Invoke(l, db)
+	}, r.cleanup("cache"))
+	sl.ProvideFuncCleanup(l, app, func(l *sl.ServiceLocator) (string, error) {
+		_, _, err := sl.Use2(l, db, cache)
+		return "app", err
+	}, r.cleanup("app"))
+
+	sl.MustInvoke(l, app)
+}
+
+func TestClose(t *testing.T) {
+	l := sl.New()
+
+	r := &closeRecorder{}
+	provideGraph(l, r)
+
+	unused := sl.NewSlot[string]()
+	sl.ProvideFuncCleanup(l, unused, func(l *sl.ServiceLocator) (string, error) {
+		return "unused", nil
+	}, r.cleanup("unused"))
+
+	errClose := errors.New("close error")
+	failing := sl.NewSlot[string]()
+	sl.ProvideFuncCleanup(l, failing, func(l *sl.ServiceLocator) (string, error) {
+		return "failing", nil
+	}, func(string) error { return errClose })
+	sl.MustInvoke(l, failing)
+
+	err := l.Close()
+	assert.Assert(t, errors.Is(err, errClose))
+	assert.DeepEqual(t, r.closed, []string{"app", "cache", "db"})
+}
+
+func TestCloseTwice(t *testing.T) {
+	l := sl.New()
+
+	r := &closeRecorder{}
+	provideGraph(l, r)
+
+	errClose := errors.New("close error")
+	failing := sl.NewSlot[string]()
+	sl.ProvideFuncCleanup(l, failing, func(l *sl.ServiceLocator) (string, error) {
+		return "failing", nil
+	}, func(string) error { return errClose })
+	sl.MustInvoke(l, failing)
+
+	err := l.Close()
+	assert.Assert(t, errors.Is(err, errClose))
+
+	assert.Equal(t, l.Close(), err)
+	assert.Equal(t, l.CloseConcurrent(context.Background()), err)
+	assert.DeepEqual(t, r.closed, []string{"app", "cache", "db"})
+}
+
+func TestCloseConcurrent(t *testing.T) {
+	l := sl.New()
+
+	r := &closeRecorder{}
+	provideGraph(l, r)
+
+	assert.NilError(t, l.CloseConcurrent(context.Background()))
+	assert.DeepEqual(t, r.closed, []string{"app", "cache", "db"})
+}
+
+func TestCloseConcurrentTimeout(t *testing.T) {
+	l := sl.New()
+
+	slow := sl.NewSlot[string]()
+	sl.ProvideFuncCleanup(l, slow, func(l *sl.ServiceLocator) (string, error) {
+		return "slow", nil
+	}, func(string) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+	sl.MustInvoke(l, slow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.CloseConcurrent(ctx)
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestSetAutoCloser(t *testing.T) {
+	l := sl.New()
+
+	closed := map[string]int{}
+	closer := func(name string) closerFunc {
+		return func() error {
+			closed[name]++
+			return nil
+		}
+	}
+
+	manualSlot := sl.NewSlot[closerFunc]()
+	sl.Provide(l, manualSlot, closer("manual"))
+
+	l.SetAutoCloser(true)
+
+	sl.Provide(l, closerSlotA, closer("a"))
+	sl.ProvideFunc(l, closerSlotB, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("b"), nil
+	})
+
+	unusedSlot := sl.NewSlot[closerFunc]()
+	sl.ProvideFunc(l, unusedSlot, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("unused"), nil
+	})
+
+	sl.MustInvoke(l, closerSlotB)
+	assert.DeepEqual(t, l.ConfigureOrder(), []string{"sl_test.closerFunc"})
+
+	assert.NilError(t, l.Close())
+	assert.DeepEqual(t, closed, map[string]int{"a": 1, "b": 1})
+}
+
+func TestProvideFuncWithShutdownPriority(t *testing.T) {
+	l := sl.New()
+
+	closed := []string{}
+	closer := func(name string) closerFunc {
+		return func() error {
+			closed = append(closed, name)
+			return nil
+		}
+	}
+
+	storeSlot := sl.NewSlot[closerFunc]()
+	bufferSlot := sl.NewSlot[closerFunc]()
+
+	sl.ProvideFuncWithShutdownPriority(l, storeSlot, 0, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("store"), nil
+	})
+	sl.ProvideFuncWithShutdownPriority(l, bufferSlot, 10, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("buffer"), nil
+	})
+	sl.ProvideFuncWithShutdownPriority(l, closerSlotA, 0, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("a"), nil
+	})
+
+	sl.MustInvoke(l, bufferSlot)
+	sl.MustInvoke(l, storeSlot)
+	sl.MustInvoke(l, closerSlotA)
+
+	assert.NilError(t, l.Close())
+	assert.DeepEqual(t, closed, []string{"buffer", "a", "store"})
+}
+
+func TestProvideFuncFlushBefore(t *testing.T) {
+	l := sl.New()
+
+	closed := []string{}
+	closer := func(name string) closerFunc {
+		return func() error {
+			closed = append(closed, name)
+			return nil
+		}
+	}
+
+	clientSlot := sl.NewSlot[closerFunc]()
+	metricsSlot := sl.NewSlot[closerFunc]()
+
+	sl.ProvideFuncFlushBefore(l, metricsSlot, []any{clientSlot}, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("metrics"), nil
+	})
+	sl.ProvideFuncWithShutdownPriority(l, clientSlot, 10, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("client"), nil
+	})
+	sl.ProvideFuncWithShutdownPriority(l, closerSlotA, 0, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("a"), nil
+	})
+
+	sl.MustInvoke(l, metricsSlot)
+	sl.MustInvoke(l, closerSlotA)
+	sl.MustInvoke(l, clientSlot)
+
+	assert.NilError(t, l.Close())
+	assert.DeepEqual(t, closed, []string{"a", "metrics", "client"})
+
+	l = sl.New()
+	closed = []string{}
+
+	sl.ProvideFuncFlushBefore(l, metricsSlot, []any{clientSlot}, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("metrics"), nil
+	})
+	sl.ProvideFuncFlushBefore(l, clientSlot, []any{metricsSlot}, func(l *sl.ServiceLocator) (closerFunc, error) {
+		return closer("client"), nil
+	})
+
+	sl.MustInvoke(l, metricsSlot)
+	sl.MustInvoke(l, clientSlot)
+
+	err := l.Close()
+	assert.ErrorContains(t, err, "contradictory flush constraints")
+	assert.DeepEqual(t, closed, []string{"client", "metrics"})
+}
+
+func TestRegisterCleanup(t *testing.T) {
+	l := sl.New()
+
+	r := &closeRecorder{}
+	l.RegisterCleanup("tempdir", func() error {
+		return r.cleanup("tempdir")("")
+	})
+	provideGraph(l, r)
+
+	errCleanup := errors.New("cleanup error")
+	l.RegisterCleanup("failing", func() error { return errCleanup })
+
+	err := l.Close()
+	assert.Assert(t, errors.Is(err, errCleanup))
+	assert.ErrorContains(t, err, "closing failing: cleanup error")
+	assert.DeepEqual(t, r.closed, []string{"app", "cache", "db", "tempdir"})
+	assert.DeepEqual(t, l.ConfigureOrder(), []string{"string", "string", "string"})
+}
+
+// countingCloser counts how many times it got closed
+type countingCloser struct {
+	closed int
+}
+
+func (c *countingCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestProvideWithCleanup(t *testing.T) {
+	l := sl.New()
+	l.SetAutoCloser(true)
+
+	fileSlot := sl.NewSlot[*countingCloser]()
+
+	file := &countingCloser{}
+	sl.Provide(l, fileSlot, file)
+
+	flushed := 0
+	sl.ProvideWithCleanup(l, fileSlot, file, func(c *countingCloser) error {
+		flushed++
+		return c.Close()
+	})
+
+	other := &countingCloser{}
+	otherSlot := sl.NewSlot[*countingCloser]()
+	sl.ProvideWithCleanup(l, otherSlot, other, (*countingCloser).Close)
+
+	assert.NilError(t, l.Close())
+	assert.Equal(t, file.closed, 1)
+	assert.Equal(t, flushed, 1)
+	assert.Equal(t, other.closed, 1)
+}
+
+func TestProvideFuncCleanupNilInterface(t *testing.T) {
+	l := sl.New()
+
+	cleanups := 0
+	sl.ProvideFuncCleanup(l, GreeterSlot, func(l *sl.ServiceLocator) (Greeter, error) {
+		return nil, nil
+	}, func(Greeter) error {
+		cleanups++
+		return nil
+	})
+
+	sl.MustInvoke(l, GreeterSlot)
+	assert.NilError(t, l.Close())
+	assert.Equal(t, cleanups, 0)
+}
+
+func TestProvideWithCleanupNilInterface(t *testing.T) {
+	l := sl.New()
+
+	cleanups := 0
+	sl.ProvideWithCleanup(l, GreeterSlot, nil, func(Greeter) error {
+		cleanups++
+		return nil
+	})
+
+	assert.NilError(t, l.Close())
+	assert.Equal(t, cleanups, 0)
+}
+
+func TestSetAutoCloserOnUseAndScoped(t *testing.T) {
+	l := sl.New()
+	l.SetAutoCloser(true)
+
+	defaultSlot := sl.NewSlot[*countingCloser]()
+	def, err := sl.UseOrProvide(l, defaultSlot, func(l *sl.ServiceLocator) (*countingCloser, error) {
+		return &countingCloser{}, nil
+	})
+	assert.NilError(t, err)
+
+	scopedSlot := sl.NewSlot[*countingCloser]()
+	sl.ProvideScoped(l, scopedSlot, func(l *sl.ServiceLocator) (*countingCloser, error) {
+		return &countingCloser{}, nil
+	})
+
+	child := l.Scope()
+	scoped := sl.MustUse(child, scopedSlot)
+	root := sl.MustUse(l, scopedSlot)
+	assert.Assert(t, scoped != root)
+
+	assert.NilError(t, child.Close())
+	assert.Equal(t, scoped.closed, 1)
+	assert.Equal(t, root.closed, 0)
+
+	assert.NilError(t, l.Close())
+	assert.Equal(t, def.closed, 1)
+	assert.Equal(t, root.closed, 1)
+	assert.Equal(t, scoped.closed, 1)
+}
@@ -0,0 +1,99 @@
+package sl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LintCategory tells which kind of problem a [LintWarning] is about
+type LintCategory string
+
+const (
+	// LintTypeNameCollision is about more slots sharing the same type name
+	LintTypeNameCollision LintCategory = "type-name-collision"
+
+	// LintEmptyHook is about hooks with no listeners
+	LintEmptyHook LintCategory = "empty-hook"
+
+	// LintUnusedSlot is about lazy slots that were never configured
+	LintUnusedSlot LintCategory = "unused-slot"
+
+	// LintNilValue is about slots eagerly provided with a nil value
+	LintNilValue LintCategory = "nil-value"
+)
+
+// LintWarning is a possible wiring mistake found by [ServiceLocator.Lint]
+type LintWarning struct {
+	Category LintCategory
+	Message  string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf(`%s: %s`, w.Category, w.Message)
+}
+
+// Lint reports common wiring mistakes of this locator: slots sharing the same
+// type name, hooks with no listeners, lazy slots never configured and slots
+// eagerly provided with a nil value.
+//
+// Unused slots are reported only if the check is run after the application
+// used its services (for example at the end of a startup self-check or of a
+// test). This doesn't configure any slot.
+func (l *ServiceLocator) Lint() []LintWarning {
+	warnings := []LintWarning{}
+
+	collisions := l.TypeNameCollisions()
+	collidingNames := make([]string, 0, len(collisions))
+	for typeName := range collisions {
+		collidingNames = append(collidingNames, typeName)
+	}
+	sort.Strings(collidingNames)
+
+	for _, typeName := range collidingNames {
+		warnings = append(warnings, LintWarning{
+			Category: LintTypeNameCollision,
+			Message:  fmt.Sprintf(`type name %s is shared by %d slots`, typeName, collisions[typeName]),
+		})
+	}
+
+	l.mu.Lock()
+	emptyHooks := []string{}
+	for _, h := range l.hooks {
+		if len(h.listeners) == 0 {
+			emptyHooks = append(emptyHooks, h.typeName)
+		}
+	}
+
+	nilValues := []string{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if s.configureFunc == nil && s.extractFunc == nil && isNil(s.value) {
+			nilValues = append(nilValues, s.typeName)
+		}
+	}
+	l.mu.Unlock()
+
+	sort.Strings(emptyHooks)
+	for _, typeName := range emptyHooks {
+		warnings = append(warnings, LintWarning{
+			Category: LintEmptyHook,
+			Message:  fmt.Sprintf(`hook of type %s has no listeners`, typeName),
+		})
+	}
+
+	for _, typeName := range l.UnusedSlots() {
+		warnings = append(warnings, LintWarning{
+			Category: LintUnusedSlot,
+			Message:  fmt.Sprintf(`lazy slot of type %s was never configured`, typeName),
+		})
+	}
+
+	for _, typeName := range nilValues {
+		warnings = append(warnings, LintWarning{
+			Category: LintNilValue,
+			Message:  fmt.Sprintf(`slot of type %s was provided with a nil value`, typeName),
+		})
+	}
+
+	return warnings
+}
@@ -0,0 +1,36 @@
+package sl_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestLint(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{})
+	sl.Provide(l, sl.NewSlot[*Config](), &Config{})
+	sl.Provide(l, LoggerSlot, nil)
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{}, nil
+	})
+	sl.ProvideHook(l, sl.NewHook[string]())
+
+	warnings := []string{}
+	for _, w := range l.Lint() {
+		warnings = append(warnings, w.String())
+	}
+
+	assert.DeepEqual(t, warnings, []string{
+		"type-name-collision: type name *sl_test.Config is shared by 2 slots",
+		"empty-hook: hook of type string has no listeners",
+		"unused-slot: lazy slot of type *sl_test.ExampleService was never configured",
+		"nil-value: slot of type *log.Logger was provided with a nil value",
+	})
+
+	clean := sl.New()
+	sl.Provide(clean, ConfigSlot, &Config{})
+	assert.DeepEqual(t, clean.Lint(), []sl.LintWarning{})
+}
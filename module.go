@@ -0,0 +1,94 @@
+package sl
+
+// Module is a set of registrations that can be installed in a
+// [ServiceLocator], like a [ModuleBuilder] or a [ModuleFunc].
+type Module interface {
+	Install(l *ServiceLocator)
+}
+
+// ModuleFunc adapts a function registering services and hooks to a [Module]
+type ModuleFunc func(*ServiceLocator)
+
+// Install calls "f" with "l"
+func (f ModuleFunc) Install(l *ServiceLocator) {
+	f(l)
+}
+
+// ProvideBy is like [Provide] but records that the slot got provided by
+// "module", the name is reported by [ServiceLocator.Stats] and a warning is
+// logged when a slot provided by a module is provided again by another one.
+// This helps diagnosing conflicts between the modules of a large application.
+func ProvideBy[T any](l *ServiceLocator, slotKey slot[T], module string, value T) T {
+	return provideValue(l, slotKey, value, module)
+}
+
+// ProvideFuncBy is like [ProvideFunc] but records that the slot got provided
+// by "module" like [ProvideBy], the name is also reported in the error
+// returned when the slot fails to configure.
+func ProvideFuncBy[T any](l *ServiceLocator, slotKey slot[T], module string, createFunc func(*ServiceLocator) (T, error)) {
+	provideFunc(l, slotKey, createFunc, module)
+}
+
+// ModuleBuilder accumulates the registrations of a module (its services and
+// hook listeners) so that they can be installed together in a
+// [ServiceLocator]. Create one with [Define].
+//
+// As generic functions can't be methods, each registration is a closure
+// calling the corresponding package level function, for example
+//
+//	var Module = sl.Define().
+//		ProvideFunc(func(l *sl.ServiceLocator) { sl.ProvideFunc(l, Slot, Configure) }).
+//		Hook(func(l *sl.ServiceLocator) { sl.ProvideHook(l, router.ApiHook, UseRoutes) })
+type ModuleBuilder struct {
+	providers []func(*ServiceLocator)
+	hooks     []func(*ServiceLocator)
+}
+
+// Define creates an empty [ModuleBuilder]
+func Define() *ModuleBuilder {
+	return &ModuleBuilder{}
+}
+
+// Provide adds a registration that should call [Provide]
+func (b *ModuleBuilder) Provide(register func(*ServiceLocator)) *ModuleBuilder {
+	b.providers = append(b.providers, register)
+	return b
+}
+
+// ProvideFunc adds a registration that should call [ProvideFunc] (or one of
+// its variations)
+func (b *ModuleBuilder) ProvideFunc(register func(*ServiceLocator)) *ModuleBuilder {
+	b.providers = append(b.providers, register)
+	return b
+}
+
+// Hook adds a registration that should call [ProvideHook]
+func (b *ModuleBuilder) Hook(register func(*ServiceLocator)) *ModuleBuilder {
+	b.hooks = append(b.hooks, register)
+	return b
+}
+
+// Install applies all the registrations of this module to "l", services are
+// registered first (in the order they were added) and then hooks.
+func (b *ModuleBuilder) Install(l *ServiceLocator) {
+	for _, register := range b.providers {
+		register(l)
+	}
+	for _, register := range b.hooks {
+		register(l)
+	}
+}
+
+// InstallHooks calls all the "registrars" in order on "l", each of them should
+// register the listeners of a hook with [ProvideHook]. This groups the wiring
+// of many hooks with different payload types in a single block
+//
+//	sl.InstallHooks(l,
+//		func(l *sl.ServiceLocator) { sl.ProvideHook(l, router.ApiHook, UseRoutes) },
+//		func(l *sl.ServiceLocator) { sl.ProvideHook(l, cron.JobsHook, UseJobs) },
+//	)
+func InstallHooks(l *ServiceLocator, registrars ...func(*ServiceLocator)) {
+	for _, register := range registrars {
+		register(l)
+	}
+}
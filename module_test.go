@@ -0,0 +1,106 @@
+package sl_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestModuleBuilder(t *testing.T) {
+	exampleHook := sl.NewHook[*[]string]()
+
+	module := sl.Define().
+		Provide(func(l *sl.ServiceLocator) {
+			sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+		}).
+		ProvideFunc(func(l *sl.ServiceLocator) {
+			sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+				config, err := sl.Use(l, ConfigSlot)
+				if err != nil {
+					return nil, err
+				}
+
+				return &ExampleService{Bar: config.Foo + " baz"}, nil
+			})
+		}).
+		Hook(func(l *sl.ServiceLocator) {
+			sl.ProvideHook(l, exampleHook, func(l *sl.ServiceLocator, routes *[]string) error {
+				*routes = append(*routes, sl.MustUse(l, ExampleServiceSlot).Bar)
+				return nil
+			})
+		})
+
+	l := sl.New()
+	module.Install(l)
+
+	routes := []string{}
+	sl.MustUseHook(l, exampleHook, &routes)
+
+	assert.DeepEqual(t, routes, []string{"foo baz"})
+}
+
+func TestInstallHooks(t *testing.T) {
+	namesHook := sl.NewHook[*[]string]()
+	countHook := sl.NewHook[*int]()
+
+	l := sl.New()
+
+	sl.InstallHooks(l,
+		func(l *sl.ServiceLocator) {
+			sl.ProvideHook(l, namesHook, func(l *sl.ServiceLocator, names *[]string) error {
+				*names = append(*names, "foo")
+				return nil
+			})
+		},
+		func(l *sl.ServiceLocator) {
+			sl.ProvideHook(l, countHook, func(l *sl.ServiceLocator, count *int) error {
+				*count++
+				return nil
+			})
+		},
+	)
+
+	names, err := sl.CollectHook(l, namesHook)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"foo"})
+
+	count, err := sl.CollectHook(l, countHook)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 1)
+}
+
+func TestProvideBy(t *testing.T) {
+	l := sl.New()
+
+	var buf bytes.Buffer
+	defer l.SetLogger(log.New(&buf, "", 0))()
+
+	sl.ProvideBy(l, ConfigSlot, "config", &Config{Foo: "foo"})
+
+	errBroken := errors.New("broken")
+	sl.ProvideFuncBy(l, ExampleServiceSlot, "example", func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return nil, errBroken
+	})
+
+	_, err := sl.Use(l, ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, errBroken))
+	assert.Error(t, err, `configuring *sl_test.ExampleService (provided by "example"): broken`)
+
+	stats := l.Stats()
+	assert.Equal(t, stats[0].Module, "config")
+	assert.Equal(t, stats[1].Module, "example")
+
+	assert.Assert(t, !strings.Contains(buf.String(), "provided by both"))
+
+	sl.ProvideBy(l, ConfigSlot, "config", &Config{Foo: "bar"})
+	sl.Provide(l, ExampleServiceSlot, &ExampleService{})
+	assert.Assert(t, !strings.Contains(buf.String(), "provided by both"))
+
+	sl.ProvideBy(l, ConfigSlot, "plugin", &Config{Foo: "baz"})
+	assert.Assert(t, strings.Contains(buf.String(), `[slot: *sl_test.Config] warning: provided by both "config" and "plugin"`))
+}
@@ -0,0 +1,212 @@
+package sl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProvideMulti adds "value" to the group of values of the given slot, unlike
+// [Provide] this doesn't replace the values already provided. All the members
+// of a group can be resolved with [UseAll], this is useful for plugins and
+// other extension points where many modules contribute an implementation.
+//
+// Groups are separate from the value of the slot itself, so [Use] doesn't see
+// the members of a group. A group provided in a child scope shadows the group
+// of its parent.
+//
+// Members are provided with priority zero, see [ProvideMultiWithPriority].
+func ProvideMulti[T any](l *ServiceLocator, groupKey slot[T], value T) {
+	ProvideMultiWithPriority(l, groupKey, 0, value)
+}
+
+// ProvideMultiWithPriority is like [ProvideMulti] but members with a higher
+// "priority" come first in the group, members with the same priority are in
+// registration order. This lets independent modules contribute to ordered
+// extension points (like a middleware chain) without coordinating the order
+// of their registrations.
+func ProvideMultiWithPriority[T any](l *ServiceLocator, groupKey slot[T], priority int, value T) {
+	typeName := slotName(groupKey)
+
+	l.logf(`[group: %s] provided member of type %T with priority %d`, typeName, value, priority)
+
+	l.addGroupMember(groupKey, &slotEntry{
+		typeName:      typeName,
+		configured:    true,
+		value:         value,
+		groupPriority: priority,
+	})
+}
+
+// ProvideMultiFunc is like [ProvideMulti] but the member is created lazily
+// like for [ProvideFunc], the first time the group gets resolved.
+func ProvideMultiFunc[T any](l *ServiceLocator, groupKey slot[T], createFunc func(*ServiceLocator) (T, error)) {
+	ProvideMultiFuncWithPriority(l, groupKey, 0, createFunc)
+}
+
+// ProvideMultiFuncWithPriority is like [ProvideMultiFunc] but the member is
+// ordered by "priority" like for [ProvideMultiWithPriority].
+func ProvideMultiFuncWithPriority[T any](l *ServiceLocator, groupKey slot[T], priority int, createFunc func(*ServiceLocator) (T, error)) {
+	typeName := slotName(groupKey)
+
+	l.logf(`[group: %s] inject lazy member provider with priority %d`, typeName, priority)
+
+	l.addGroupMember(groupKey, &slotEntry{
+		typeName:      typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+		groupPriority: priority,
+	})
+}
+
+// addGroupMember inserts a member in the group of the given slot key after
+// the members with a greater or equal priority
+func (l *ServiceLocator) addGroupMember(groupKey any, entry *slotEntry) {
+	l.mu.Lock()
+	if l.sealed {
+		l.mu.Unlock()
+		l.logf(`[group: %s] cannot provide in a sealed locator, ignored`, entry.typeName)
+		return
+	}
+
+	members := l.groups[groupKey]
+	i := len(members)
+	for i > 0 && members[i-1].groupPriority < entry.groupPriority {
+		i--
+	}
+
+	members = append(members, nil)
+	copy(members[i+1:], members[i:])
+	members[i] = entry
+	l.groups[groupKey] = members
+	l.mu.Unlock()
+
+	l.emit(Event{Kind: EventProvide, TypeName: entry.typeName})
+}
+
+// lookupGroup searches the members of the group of "groupKey" in this locator
+// and then in its parent scopes, it also returns the locator owning the group.
+// The lock of the locator must be held.
+func (l *ServiceLocator) lookupGroup(groupKey any) ([]*slotEntry, *ServiceLocator, bool) {
+	for current := l; current != nil; current = current.parent {
+		if members, ok := current.groups[groupKey]; ok {
+			return append([]*slotEntry{}, members...), current, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// useGroupValues configures all the members of a group and returns the values
+// of the ones configured successfully together with the errors of the others.
+// This returns false if the group has no members.
+func useGroupValues[T any](l *ServiceLocator, groupKey slot[T]) ([]T, []error, bool) {
+	members, results, errs, ok := useMembers[T](l, `group `+getTypeName[T](), func() ([]*slotEntry, *ServiceLocator, bool) {
+		return l.lookupGroup(groupKey)
+	})
+	if !ok {
+		return []T{}, errs, len(errs) > 0
+	}
+
+	values := []T{}
+	memberErrs := []error{}
+	for i := range members {
+		if errs[i] != nil {
+			memberErrs = append(memberErrs, fmt.Errorf(`member %d: %w`, i, errs[i]))
+			continue
+		}
+
+		values = append(values, results[i])
+	}
+
+	return values, memberErrs, true
+}
+
+// useMembers configures the entries returned by "lookup", called with the
+// lock held, and returns their values and errors by position. The slots used
+// to configure them are recorded as dependencies of the slot being configured
+// by "l", if any. This returns false if there are no entries, with a single
+// error if they can't be used from the current goroutine. The "name" is used
+// to describe the entries in errors.
+func useMembers[T any](l *ServiceLocator, name string, lookup func() ([]*slotEntry, *ServiceLocator, bool)) ([]*slotEntry, []T, []error, bool) {
+	l.mu.Lock()
+	if err := l.checkGoroutine(); err != nil {
+		l.mu.Unlock()
+		return nil, nil, []error{fmt.Errorf(`using %s: %w`, name, err)}, false
+	}
+
+	members, owner, ok := lookup()
+	if !ok || len(members) == 0 {
+		l.mu.Unlock()
+		return nil, nil, nil, false
+	}
+
+	values := make([]T, len(members))
+	errs := make([]error, len(members))
+	for i, member := range members {
+		if !member.configured {
+			if err := l.configuring.cycleError(member); err != nil {
+				errs[i] = err
+				continue
+			}
+		}
+
+		if l.dependent != nil {
+			l.dependent.addDependency(member)
+		}
+	}
+	l.mu.Unlock()
+
+	if l.ctx != nil || l.configuring != nil {
+		owner = owner.resolutionView(l)
+	}
+
+	for i, member := range members {
+		if errs[i] != nil {
+			continue
+		}
+
+		v, err := member.ensureConfigured(owner)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		values[i], errs[i] = assertSlotValue[T](v)
+	}
+
+	return members, values, errs, true
+}
+
+// UseAll resolves all the members of the group of the given slot in priority
+// and then registration order, configuring the lazy ones. This returns an
+// error if the group has no members or if any of them fails to configure.
+func UseAll[T any](l *ServiceLocator, groupKey slot[T]) ([]T, error) {
+	values, errs, ok := useGroupValues(l, groupKey)
+	if !ok {
+		return nil, fmt.Errorf(`%w for group of type %s`, ErrSlotNotFound, getTypeName[T]())
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return values, nil
+}
+
+// UseAllOptional is like [UseAll] but a missing or empty group resolves to an
+// empty slice, and members failing to configure are logged and skipped. This
+// is useful for optional extension points, see [UseAllCollect] to handle the
+// errors of the members.
+func UseAllOptional[T any](l *ServiceLocator, groupKey slot[T]) []T {
+	values, errs := UseAllCollect(l, groupKey)
+	if errs != nil {
+		l.logf(`[group: %s] skipped members: %v`, getTypeName[T](), errs)
+	}
+
+	return values
+}
+
+// UseAllCollect is like [UseAllOptional] but also returns the joined errors
+// of the members that failed to configure.
+func UseAllCollect[T any](l *ServiceLocator, groupKey slot[T]) ([]T, error) {
+	values, errs, _ := useGroupValues(l, groupKey)
+	return values, errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package sl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestUseAll(t *testing.T) {
+	pluginSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	_, err := sl.UseAll(l, pluginSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	created := 0
+	sl.ProvideMulti(l, pluginSlot, "a")
+	sl.ProvideMultiFunc(l, pluginSlot, func(l *sl.ServiceLocator) (string, error) {
+		created++
+		return sl.MustUse(l, ConfigSlot).Foo, nil
+	})
+
+	plugins, err := sl.UseAll(l, pluginSlot)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, plugins, []string{"a", "foo"})
+
+	sl.UseAll(l, pluginSlot)
+	assert.Equal(t, created, 1)
+
+	_, err = sl.Use(l, pluginSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+func TestUseAllOptional(t *testing.T) {
+	pluginSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+	assert.DeepEqual(t, sl.UseAllOptional(l, pluginSlot), []string{})
+
+	errBroken := errors.New("broken")
+	sl.ProvideMulti(l, pluginSlot, "a")
+	sl.ProvideMultiFunc(l, pluginSlot, func(l *sl.ServiceLocator) (string, error) {
+		return "", errBroken
+	})
+	sl.ProvideMulti(l, pluginSlot, "c")
+
+	assert.DeepEqual(t, sl.UseAllOptional(l, pluginSlot), []string{"a", "c"})
+
+	plugins, err := sl.UseAllCollect(l, pluginSlot)
+	assert.DeepEqual(t, plugins, []string{"a", "c"})
+	assert.Assert(t, errors.Is(err, errBroken))
+	assert.ErrorContains(t, err, "member 1: configuring string: broken")
+
+	_, err = sl.UseAll(l, pluginSlot)
+	assert.Assert(t, errors.Is(err, errBroken))
+}
+
+func TestProvideMultiWithPriority(t *testing.T) {
+	middlewareSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	sl.ProvideMulti(l, middlewareSlot, "handler")
+	sl.ProvideMultiWithPriority(l, middlewareSlot, 10, "logging")
+	sl.ProvideMultiFuncWithPriority(l, middlewareSlot, 20, func(l *sl.ServiceLocator) (string, error) {
+		return "recover", nil
+	})
+	sl.ProvideMultiWithPriority(l, middlewareSlot, 10, "metrics")
+	sl.ProvideMultiWithPriority(l, middlewareSlot, -5, "not found")
+	sl.ProvideMultiFunc(l, middlewareSlot, func(l *sl.ServiceLocator) (string, error) {
+		return "static", nil
+	})
+
+	middlewares, err := sl.UseAll(l, middlewareSlot)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, middlewares, []string{"recover", "logging", "metrics", "handler", "static", "not found"})
+}
@@ -0,0 +1,58 @@
+package sl
+
+// Optional holds a value or nothing, see [UseOption]
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an [Optional] holding "value"
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value, true}
+}
+
+// None returns an empty [Optional]
+func None[T any]() Optional[T] {
+	return Optional[T]{}
+}
+
+// Value returns the value and true, or the zero value and false if empty
+func (o Optional[T]) Value() (T, bool) {
+	return o.value, o.ok
+}
+
+// Result holds either a value or the error that prevented creating it, see
+// [UseResult]
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Value returns the value and a nil error, or the zero value and the error
+func (r Result[T]) Value() (T, error) {
+	return r.value, r.err
+}
+
+// Err returns the error of the result, nil if it holds a value
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// UseOption resolves a slot like [Use] and returns an [Optional] holding its
+// value, or an empty one if the slot can't be resolved (because it is
+// missing or fails to configure).
+func UseOption[T any](l *ServiceLocator, slotKey slot[T]) Optional[T] {
+	v, err := Use(l, slotKey)
+	if err != nil {
+		return None[T]()
+	}
+
+	return Some(v)
+}
+
+// UseResult resolves a slot like [Use] and returns a [Result] with its value
+// or the error.
+func UseResult[T any](l *ServiceLocator, slotKey slot[T]) Result[T] {
+	v, err := Use(l, slotKey)
+	return Result[T]{v, err}
+}
@@ -0,0 +1,51 @@
+package sl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestUseOption(t *testing.T) {
+	l := sl.New()
+
+	_, ok := sl.UseOption(l, ConfigSlot).Value()
+	assert.Assert(t, !ok)
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	config, ok := sl.UseOption(l, ConfigSlot).Value()
+	assert.Assert(t, ok)
+	assert.Equal(t, config.Foo, "foo")
+
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return nil, errors.New("broken")
+	})
+
+	_, ok = sl.UseOption(l, ExampleServiceSlot).Value()
+	assert.Assert(t, !ok)
+}
+
+func TestUseResult(t *testing.T) {
+	l := sl.New()
+
+	result := sl.UseResult(l, ConfigSlot)
+	assert.Assert(t, errors.Is(result.Err(), sl.ErrSlotNotFound))
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	config, err := sl.UseResult(l, ConfigSlot).Value()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+
+	errBroken := errors.New("broken")
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return nil, errBroken
+	})
+
+	service, err := sl.UseResult(l, ExampleServiceSlot).Value()
+	assert.Assert(t, errors.Is(err, errBroken))
+	assert.Assert(t, service == nil)
+}
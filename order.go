@@ -0,0 +1,73 @@
+package sl
+
+import "fmt"
+
+// ConfigureOrder returns the type names of the configured lazy slots of this
+// locator in the order they got configured, this can be passed to
+// [ServiceLocator.ForceOrder] to reproduce bugs depending on the construction
+// order of services. Group members and keyed instances aren't slots so they
+// are not included.
+func (l *ServiceLocator) ConfigureOrder() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// only the entries ForceOrder can find by type name
+	slots := map[*slotEntry]bool{}
+	for _, key := range l.slotKeys {
+		slots[l.providers[key]] = true
+	}
+
+	names := []string{}
+	for _, s := range l.configuredEntries() {
+		if s.configureFunc == nil || !slots[s] {
+			continue
+		}
+
+		names = append(names, s.typeName)
+	}
+
+	return names
+}
+
+// ForceOrder configures the slots of this locator with the given type names
+// in the given order, slots already configured are left as is. This returns
+// an error if a type name is not registered or is shared by more than one
+// slot (see [ServiceLocator.TypeNameCollisions]), or if a slot fails to
+// configure.
+func (l *ServiceLocator) ForceOrder(typeNames []string) error {
+	l.mu.Lock()
+	entries := make([]*slotEntry, len(typeNames))
+	for i, typeName := range typeNames {
+		for _, key := range l.slotKeys {
+			s := l.providers[key]
+			if s.typeName != typeName {
+				continue
+			}
+
+			if entries[i] != nil {
+				l.mu.Unlock()
+				return fmt.Errorf(`type name %s is ambiguous`, typeName)
+			}
+
+			entries[i] = s
+		}
+
+		if entries[i] == nil {
+			l.mu.Unlock()
+			return fmt.Errorf(`%w for type %s`, ErrSlotNotFound, typeName)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, s := range entries {
+		if s.extractFunc != nil {
+			return fmt.Errorf(`slot of type %s is derived from the context and can't be configured`, s.typeName)
+		}
+
+		if _, _, _, err := l.useEntry(s.slotKey, s.typeName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package sl_test
+
+import (
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestConfigureOrder(t *testing.T) {
+	provideAll := func(l *sl.ServiceLocator) {
+		sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+			return &Config{}, nil
+		})
+		sl.ProvideFunc(l, LoggerSlot, func(l *sl.ServiceLocator) (*log.Logger, error) {
+			return log.Default(), nil
+		})
+		sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+			return &ExampleService{Logger: sl.MustUse(l, LoggerSlot)}, nil
+		})
+	}
+
+	l1 := sl.New()
+	provideAll(l1)
+
+	sl.MustInvoke(l1, ExampleServiceSlot)
+	sl.MustInvoke(l1, ConfigSlot)
+
+	order := l1.ConfigureOrder()
+	assert.DeepEqual(t, order, []string{"*log.Logger", "*sl_test.ExampleService", "*sl_test.Config"})
+
+	l2 := sl.New()
+	provideAll(l2)
+
+	assert.NilError(t, l2.ForceOrder(order))
+	assert.DeepEqual(t, l2.ConfigureOrder(), order)
+
+	err := l2.ForceOrder([]string{"*sl_test.Missing"})
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	sl.Provide(l2, sl.NewSlot[*Config](), &Config{})
+	assert.Error(t, l2.ForceOrder([]string{"*sl_test.Config"}), "type name *sl_test.Config is ambiguous")
+}
+
+func TestConfigureOrderRoundTrip(t *testing.T) {
+	provideAll := func(l *sl.ServiceLocator) {
+		sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+			return &Config{}, nil
+		})
+		sl.ProvideMultiFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+			return &ExampleService{}, nil
+		})
+		sl.ProvideKeyedFunc(l, LoggerSlot, "audit", func(l *sl.ServiceLocator) (*log.Logger, error) {
+			return log.Default(), nil
+		})
+	}
+
+	l1 := sl.New()
+	provideAll(l1)
+
+	_, err := sl.UseAll(l1, ExampleServiceSlot)
+	assert.NilError(t, err)
+	_, err = sl.UseKeyed(l1, LoggerSlot, "audit")
+	assert.NilError(t, err)
+	sl.MustInvoke(l1, ConfigSlot)
+
+	order := l1.ConfigureOrder()
+	assert.DeepEqual(t, order, []string{"*sl_test.Config"})
+
+	l2 := sl.New()
+	provideAll(l2)
+
+	assert.NilError(t, l2.ForceOrder(order))
+	assert.DeepEqual(t, l2.ConfigureOrder(), order)
+}
@@ -0,0 +1,112 @@
+package sl
+
+// providerChange records the previous entry of a slot key before it got
+// replaced, this is used to undo the change.
+type providerChange struct {
+	slotKey  any
+	previous *slotEntry
+	existed  bool
+}
+
+// undoProviderChange puts back the previous entry of a slot key or removes the
+// key if there was none, the lock of the locator must be held.
+func (l *ServiceLocator) undoProviderChange(c providerChange) {
+	if c.existed {
+		l.providers[c.slotKey] = c.previous
+		return
+	}
+
+	delete(l.providers, c.slotKey)
+	for i, key := range l.slotKeys {
+		if key == c.slotKey {
+			l.slotKeys = append(l.slotKeys[:i], l.slotKeys[i+1:]...)
+			break
+		}
+	}
+}
+
+// PushProvide is like [Provide] but returns a function that puts back the
+// previous provider of the slot (or removes the slot if it had none). The
+// previous provider is restored as it was, so a lazy slot keeps its cached
+// value.
+//
+// Calling the returned function more than once has no effect.
+func PushProvide[T any](l *ServiceLocator, slotKey slot[T], value T) (pop func()) {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] pushed value of type %T`, typeName, value)
+
+	l.mu.Lock()
+	previous, existed := l.providers[slotKey]
+	ok := l.putProvider(slotKey, &slotEntry{
+		typeName:   typeName,
+		configured: true,
+		value:      value,
+	})
+	l.mu.Unlock()
+
+	if !ok {
+		return func() {}
+	}
+
+	l.emit(Event{Kind: EventProvide, TypeName: typeName})
+
+	popped := false
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if popped {
+			return
+		}
+		popped = true
+
+		l.logf(`[slot: %s] popped value`, typeName)
+		l.undoProviderChange(providerChange{slotKey, previous, existed})
+	}
+}
+
+// OverrideScope calls all the "overrides" functions (that should replace some
+// slots for example using [PushProvide] or [Provide]) and returns a function
+// that undoes all the changes they made in reverse order. This gives a simple
+// idiom for middlewares
+//
+//	defer l.OverrideScope(func(l *sl.ServiceLocator) {
+//		sl.PushProvide(l, LoggerSlot, requestLogger)
+//	})()
+//
+// As the changes are made to "l" itself they are visible to every goroutine
+// using it, so concurrent requests would clobber each other. For true
+// isolation the overrides should be applied to a child created with
+// [ServiceLocator.Scope], at the cost of creating a new scope per request.
+func (l *ServiceLocator) OverrideScope(overrides ...func(*ServiceLocator)) (restore func()) {
+	changes := []providerChange{}
+
+	l.mu.Lock()
+	outer := l.journal
+	l.journal = &changes
+	l.mu.Unlock()
+
+	for _, override := range overrides {
+		override(l)
+	}
+
+	l.mu.Lock()
+	l.journal = outer
+	l.mu.Unlock()
+
+	restored := false
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if restored {
+			return
+		}
+		restored = true
+
+		for i := len(changes) - 1; i >= 0; i-- {
+			l.undoProviderChange(changes[i])
+		}
+	}
+}
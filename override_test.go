@@ -0,0 +1,55 @@
+package sl_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestPushProvide(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+	config := sl.MustUse(l, ConfigSlot)
+
+	pop := sl.PushProvide(l, ConfigSlot, &Config{Foo: "bar"})
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "bar")
+
+	popLogger := sl.PushProvide(l, LoggerSlot, nil)
+	assert.NilError(t, sl.Invoke(l, LoggerSlot))
+
+	pop()
+	pop()
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+
+	popLogger()
+	assert.ErrorContains(t, sl.Invoke(l, LoggerSlot), "no injected value")
+	assert.DeepEqual(t, l.UnusedSlots(), []string{})
+}
+
+func TestOverrideScope(t *testing.T) {
+	l := sl.New()
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	restore := l.OverrideScope(
+		func(l *sl.ServiceLocator) {
+			sl.PushProvide(l, ConfigSlot, &Config{Foo: "bar"})
+		},
+		func(l *sl.ServiceLocator) {
+			sl.PushProvide(l, ConfigSlot, &Config{Foo: "baz"})
+			sl.Provide(l, ExampleServiceSlot, &ExampleService{Bar: "bar"})
+		},
+	)
+
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "baz")
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, "bar")
+
+	restore()
+
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+	assert.ErrorContains(t, sl.Invoke(l, ExampleServiceSlot), "no injected value")
+}
@@ -0,0 +1,60 @@
+package sl
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// proxyFactories are the factories registered with [RegisterProxy] by
+// interface type
+var proxyFactories sync.Map
+
+// RegisterProxy registers the factory used by [UseProxy] to build proxies for
+// the interface "T". Go can't create new methods at runtime (not even with
+// reflection), so the forwarding type must be written by hand, for example
+//
+//	type greeterProxy struct{ current func() Greeter }
+//
+//	func (p greeterProxy) Greet() string { return p.current().Greet() }
+//
+//	func init() {
+//		sl.RegisterProxy(func(current func() Greeter) Greeter {
+//			return greeterProxy{current}
+//		})
+//	}
+//
+// Registering a factory again for the same type replaces the previous one.
+// This panics if "T" is not an interface.
+func RegisterProxy[T any](factory func(current func() T) T) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		panic(fmt.Sprintf(`cannot register a proxy for non-interface type %s`, getTypeName[T]()))
+	}
+
+	proxyFactories.Store(t, factory)
+}
+
+// UseProxy returns a proxy implementing the interface "T" that forwards each
+// method call to the value of the given slot at the time of the call, so that
+// holders of the proxy transparently use the new implementation after an
+// [Override]. The proxy is built by the factory registered for "T" with
+// [RegisterProxy].
+//
+// Each call through the proxy resolves the slot like [MustUse], which adds a
+// lookup (and an indirect call) to every method call and panics if the slot
+// can't be resolved at that time. This panics if no proxy is registered for
+// "T", which must be an interface.
+func UseProxy[T any](l *ServiceLocator, slotKey slot[T]) T {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface {
+		panic(fmt.Sprintf(`cannot proxy non-interface type %s`, getTypeName[T]()))
+	}
+
+	factory, ok := proxyFactories.Load(t)
+	if !ok {
+		panic(fmt.Sprintf(`no proxy registered for type %s`, getTypeName[T]()))
+	}
+
+	return factory.(func(func() T) T)(MustGetter(l, slotKey))
+}
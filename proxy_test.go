@@ -0,0 +1,42 @@
+package sl_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+type greeterProxy struct{ current func() Greeter }
+
+func (p greeterProxy) Greet() string { return p.current().Greet() }
+
+func init() {
+	sl.RegisterProxy(func(current func() Greeter) Greeter {
+		return greeterProxy{current}
+	})
+}
+
+func TestUseProxy(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide[Greeter](l, GreeterSlot, &EnglishGreeter{Name: "World"})
+
+	greeter := sl.UseProxy(l, GreeterSlot)
+	assert.Equal(t, greeter.Greet(), "Hello World")
+
+	sl.Override[Greeter](l, GreeterSlot, &ItalianGreeter{Name: "Mondo"})
+	assert.Equal(t, greeter.Greet(), "Ciao Mondo")
+
+	func() {
+		defer func() {
+			assert.Equal(t, recover(), "no proxy registered for type sl_test.NamedGreeter")
+		}()
+		sl.UseProxy(l, sl.NewSlot[NamedGreeter]())
+	}()
+
+	defer func() {
+		assert.Equal(t, recover(), "cannot proxy non-interface type *sl_test.Config")
+	}()
+	sl.UseProxy(l, ConfigSlot)
+}
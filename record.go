@@ -0,0 +1,51 @@
+package sl
+
+// useRecorder collects the type names of the slots used while recording, see
+// [ServiceLocator.RecordUses]
+type useRecorder struct {
+	seen  map[*slotEntry]bool
+	names []string
+}
+
+// RecordUses starts recording the slots used through this locator and its
+// child scopes, the returned function stops the recording and returns the
+// type names of the used slots in order of first use. This lets tests assert
+// which services a unit of work depends on.
+//
+// Only direct uses are recorded, the slots used by lazy constructors while
+// configuring are not. Recordings can be nested and are safe for concurrent
+// use, calling the returned function more than once returns the same names.
+func (l *ServiceLocator) RecordUses() (stop func() []string) {
+	r := &useRecorder{seen: map[*slotEntry]bool{}, names: []string{}}
+
+	l.mu.Lock()
+	l.recorders = append(l.recorders, r)
+	l.mu.Unlock()
+
+	return func() []string {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		for i, other := range l.recorders {
+			if other == r {
+				l.recorders = append(l.recorders[:i:i], l.recorders[i+1:]...)
+				break
+			}
+		}
+
+		return append([]string{}, r.names...)
+	}
+}
+
+// recordUse adds a slot to the active recordings of this locator and of its
+// parent scopes. The lock of the locator must be held.
+func (l *ServiceLocator) recordUse(s *slotEntry) {
+	for current := l; current != nil; current = current.parent {
+		for _, r := range current.recorders {
+			if !r.seen[s] {
+				r.seen[s] = true
+				r.names = append(r.names, s.typeName)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sl_test
+
+import (
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestRecordUses(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.Provide(l, LoggerSlot, log.Default())
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		config, logger := sl.MustUse2(l, ConfigSlot, LoggerSlot)
+		return &ExampleService{Bar: config.Foo, Logger: logger}, nil
+	})
+
+	stopOuter := l.RecordUses()
+	sl.MustUse(l, ConfigSlot)
+
+	stopInner := l.RecordUses()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sl.MustUse(l.Scope(), ExampleServiceSlot)
+		}()
+	}
+	wg.Wait()
+
+	assert.DeepEqual(t, stopInner(), []string{"*sl_test.ExampleService"})
+
+	sl.MustUse(l, LoggerSlot)
+	assert.DeepEqual(t, stopOuter(), []string{"*sl_test.Config", "*sl_test.ExampleService", "*log.Logger"})
+
+	sl.MustUse(l, ConfigSlot)
+	assert.DeepEqual(t, stopInner(), []string{"*sl_test.ExampleService"})
+}
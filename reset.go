@@ -0,0 +1,227 @@
+package sl
+
+import "errors"
+
+// reset clears the cached value of a lazily provided slot, eagerly provided
+// slots can't be reset as there is no way to configure them again. Returns
+// true if the slot was actually reset. The lock of the locator must be held.
+func (s *slotEntry) reset(l *ServiceLocator) bool {
+	if s.configureFunc == nil || !s.configured {
+		return false
+	}
+
+	l.logf(`[slot: %s] reset`, s.typeName)
+
+	s.configured = false
+	s.value = nil
+	s.clearDependencies()
+
+	return true
+}
+
+// Reset clears the cached value of a lazy slot so that the next call to [Use]
+// or [Invoke] will configure it again. Eagerly provided slots (with [Provide])
+// are left untouched.
+//
+// The cleanup of the previous value is called like [ServiceLocator.Close]
+// would (see for example [ProvideFuncCleanup] and
+// [ServiceLocator.SetAutoCloser]), its error is logged.
+//
+// Returns true if the slot was configured and got reset.
+func Reset[T any](l *ServiceLocator, slotKey slot[T]) bool {
+	l.mu.Lock()
+	slot, _, ok := l.lookupProvider(slotKey)
+	l.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	return len(l.invalidateLogged([]*slotEntry{slot})) > 0
+}
+
+// ResetCascade is like [Reset] but also resets every slot that used this
+// slot (directly or indirectly) while configuring itself, so that all of them
+// get rebuilt on their next use. This is useful for example to reload a
+// configuration at runtime.
+//
+// Dependencies are only recorded when they get resolved through the
+// [ServiceLocator] passed to the "createFunc" of [ProvideFunc], services that
+// capture some other locator in a closure will not be reached by the cascade.
+//
+// The cleanups of the previous values are called like for [Reset], starting
+// from the outermost dependents.
+//
+// Returns the type names of all the slots that got reset.
+func ResetCascade[T any](l *ServiceLocator, slotKey slot[T]) []string {
+	l.mu.Lock()
+	root, _, ok := l.lookupProvider(slotKey)
+	if !ok {
+		l.mu.Unlock()
+		return nil
+	}
+
+	entries := root.transitiveDependents()
+	l.mu.Unlock()
+
+	resetNames := []string{}
+	for _, s := range l.invalidateLogged(entries) {
+		resetNames = append(resetNames, s.typeName)
+	}
+
+	return resetNames
+}
+
+// transitiveDependents returns this slot followed by all the slots that used
+// it (directly or indirectly) while configuring themselves, in breadth first
+// order. The lock of the locator must be held.
+func (s *slotEntry) transitiveDependents() []*slotEntry {
+	visited := map[*slotEntry]bool{s: true}
+	entries := []*slotEntry{s}
+
+	for i := 0; i < len(entries); i++ {
+		for _, d := range entries[i].dependents {
+			if !visited[d] {
+				visited[d] = true
+				entries = append(entries, d)
+			}
+		}
+	}
+
+	return entries
+}
+
+// OverrideInvalidate is like [Override] but also resets every slot that used
+// the previous value (directly or indirectly) while configuring itself, like
+// [ResetCascade], so that they get rebuilt against "value" on their next use.
+// This is useful to hot-reload a service at runtime.
+//
+// The cleanups of the previous values of the invalidated slots are called
+// like for [Reset], starting from the outermost dependents.
+func OverrideInvalidate[T any](l *ServiceLocator, slotKey slot[T], value T) {
+	l.mu.Lock()
+	invalidated := []*slotEntry{}
+	if previous, _, ok := l.lookupProvider(slotKey); ok {
+		invalidated = previous.transitiveDependents()[1:]
+	}
+	l.mu.Unlock()
+
+	Override(l, slotKey, value)
+
+	l.invalidateLogged(invalidated)
+}
+
+// configuredEntries returns the lazy slots of this locator that are currently
+// configured in the order they got configured, together with the eager slots
+// having a cleanup. The lock of the locator must be held.
+func (l *ServiceLocator) configuredEntries() []*slotEntry {
+	seen := map[*slotEntry]bool{}
+
+	// walk backwards to keep only the last configuration of each slot
+	entries := []*slotEntry{}
+	for i := len(l.configureOrder) - 1; i >= 0; i-- {
+		s := l.configureOrder[i]
+		if !s.configured || seen[s] {
+			continue
+		}
+
+		seen[s] = true
+		entries = append(entries, s)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	l.configureOrder = entries
+	return append([]*slotEntry{}, entries...)
+}
+
+// ResetAll resets every lazy slot of this locator so that the next call to
+// [Use] rebuilds the whole graph of services, eagerly provided slots are left
+// untouched. This is useful for example to reload the whole application
+// configuration on SIGHUP.
+//
+// After being reset, the cleanups of the previous values are called in
+// reverse configuration order (so services are closed before their
+// dependencies) like [ServiceLocator.Close] would, see for example
+// [ProvideFuncCleanup] and [ServiceLocator.SetAutoCloser]. All slots are reset
+// even if some cleanups fail, the returned error joins all their errors.
+func (l *ServiceLocator) ResetAll() error {
+	l.mu.Lock()
+	entries := l.configuredEntries()
+	l.mu.Unlock()
+
+	_, errs := l.invalidate(entries)
+	return errors.Join(errs...)
+}
+
+// invalidate resets the given slots and then calls the cleanups of their
+// previous values in reverse order, slots that can't be reset are skipped.
+// This returns the slots that got reset and the errors of the cleanups.
+func (l *ServiceLocator) invalidate(entries []*slotEntry) ([]*slotEntry, []error) {
+	l.mu.Lock()
+	reset := []*slotEntry{}
+	values := []any{}
+	for _, s := range entries {
+		value := s.value
+		if s.reset(l) {
+			reset = append(reset, s)
+			values = append(values, value)
+		}
+	}
+	l.mu.Unlock()
+
+	errs := []error{}
+	for i := len(reset) - 1; i >= 0; i-- {
+		if err := reset[i].cleanup(l, values[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return reset, errs
+}
+
+// invalidateLogged is like [ServiceLocator.invalidate] but logs the errors of
+// the cleanups
+func (l *ServiceLocator) invalidateLogged(entries []*slotEntry) []*slotEntry {
+	reset, errs := l.invalidate(entries)
+	for _, err := range errs {
+		l.logf(`warning: %v`, err)
+	}
+
+	return reset
+}
+
+// ClearEager removes all the slots of this locator provided with a value
+// (like with [Provide]) instead of a function, lazy slots are left untouched
+// with their cached values and so are slots provided with
+// [ProvideFromContext]. This is useful in tests to inject a fresh
+// configuration for each case while keeping the lazy wiring. Cleanups already
+// registered for the removed values are still called by
+// [ServiceLocator.Close].
+//
+// Slots provided in parent scopes are not removed.
+func (l *ServiceLocator) ClearEager() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sealed {
+		l.logf(`cannot clear eager slots of a sealed locator, ignored`)
+		return
+	}
+
+	slotKeys := []any{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if s.configureFunc != nil || s.extractFunc != nil {
+			slotKeys = append(slotKeys, key)
+			continue
+		}
+
+		l.logf(`[slot: %s] cleared`, s.typeName)
+
+		delete(l.providers, key)
+	}
+
+	l.slotKeys = slotKeys
+}
@@ -0,0 +1,277 @@
+package sl_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestReset(t *testing.T) {
+	l := sl.New()
+
+	configured := 0
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		configured++
+		return &Config{Foo: "foo"}, nil
+	})
+
+	assert.Equal(t, sl.Reset(l, ConfigSlot), false)
+
+	sl.MustUse(l, ConfigSlot)
+	sl.MustUse(l, ConfigSlot)
+	assert.Equal(t, configured, 1)
+
+	assert.Equal(t, sl.Reset(l, ConfigSlot), true)
+
+	sl.MustUse(l, ConfigSlot)
+	assert.Equal(t, configured, 2)
+}
+
+func TestResetEager(t *testing.T) {
+	l := sl.New()
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	assert.Equal(t, sl.Reset(l, ConfigSlot), false)
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+}
+
+func TestResetCascade(t *testing.T) {
+	l := sl.New()
+
+	foo := "foo"
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: foo}, nil
+	})
+
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		config, err := sl.Use(l, ConfigSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExampleService{Bar: config.Foo + " baz"}, nil
+	})
+
+	otherSlot := sl.NewSlot[string]()
+	sl.ProvideFunc(l, otherSlot, func(l *sl.ServiceLocator) (string, error) {
+		return "other", nil
+	})
+
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, "foo baz")
+	sl.MustUse(l, otherSlot)
+
+	foo = "bar"
+	reset := sl.ResetCascade(l, ConfigSlot)
+
+	assert.DeepEqual(t, reset, []string{"*sl_test.Config", "*sl_test.ExampleService"})
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, "bar baz")
+	assert.Equal(t, sl.Reset(l, otherSlot), true)
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+var closerSlotA = sl.NewSlot[closerFunc]()
+var closerSlotB = sl.NewSlot[closerFunc]()
+
+func TestResetAll(t *testing.T) {
+	l := sl.New()
+	l.SetAutoCloser(true)
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	closed := []string{}
+	errClose := errors.New("close error")
+
+	constructed := 0
+	sl.ProvideFunc(l, closerSlotA, func(l *sl.ServiceLocator) (closerFunc, error) {
+		constructed++
+		return func() error {
+			closed = append(closed, "a")
+			return errClose
+		}, nil
+	})
+	sl.ProvideFunc(l, closerSlotB, func(l *sl.ServiceLocator) (closerFunc, error) {
+		if err := sl.Invoke(l, closerSlotA); err != nil {
+			return nil, err
+		}
+
+		constructed++
+		return func() error {
+			closed = append(closed, "b")
+			return nil
+		}, nil
+	})
+
+	sl.MustInvoke(l, closerSlotB)
+	assert.Equal(t, constructed, 2)
+
+	err := l.ResetAll()
+	assert.Assert(t, errors.Is(err, errClose))
+	assert.DeepEqual(t, closed, []string{"b", "a"})
+
+	sl.MustInvoke(l, closerSlotB)
+	assert.Equal(t, constructed, 4)
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+}
+
+func TestOverrideInvalidate(t *testing.T) {
+	l := sl.New()
+	l.SetAutoCloser(true)
+
+	storeSlot := sl.NewSlot[string]()
+	appSlot := sl.NewSlot[string]()
+
+	closed := []string{}
+	built := []string{}
+
+	sl.Provide(l, storeSlot, "v1")
+	sl.ProvideFunc(l, closerSlotA, func(l *sl.ServiceLocator) (closerFunc, error) {
+		store := sl.MustUse(l, storeSlot)
+		built = append(built, "cache "+store)
+
+		return func() error {
+			closed = append(closed, "cache "+store)
+			return nil
+		}, nil
+	})
+	sl.ProvideFunc(l, appSlot, func(l *sl.ServiceLocator) (string, error) {
+		sl.MustInvoke(l, closerSlotA)
+		return "app " + sl.MustUse(l, storeSlot), nil
+	})
+
+	unrelatedSlot := sl.NewSlot[int]()
+	sl.ProvideFunc(l, unrelatedSlot, func(l *sl.ServiceLocator) (int, error) {
+		return 42, nil
+	})
+
+	assert.Equal(t, sl.MustUse(l, appSlot), "app v1")
+	sl.MustInvoke(l, unrelatedSlot)
+
+	sl.OverrideInvalidate(l, storeSlot, "v2")
+	assert.DeepEqual(t, closed, []string{"cache v1"})
+
+	assert.Equal(t, sl.MustUse(l, appSlot), "app v2")
+	assert.DeepEqual(t, built, []string{"cache v1", "cache v2"})
+	assert.Equal(t, sl.Reset(l, unrelatedSlot), true)
+}
+
+func TestClearEager(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	configured := 0
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		configured++
+		return &ExampleService{}, nil
+	})
+
+	service := sl.MustUse(l, ExampleServiceSlot)
+
+	l.ClearEager()
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot), service)
+	assert.Equal(t, configured, 1)
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "bar"})
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "bar")
+	assert.Equal(t, len(l.Stats()), 2)
+}
+
+func TestClearEagerContext(t *testing.T) {
+	userSlot := sl.NewSlot[string]()
+
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFromContext(l, userSlot, func(ctx context.Context) (string, error) {
+		user, _ := ctx.Value(userKey{}).(string)
+		return user, nil
+	})
+	sl.Provide(l, LoggerSlot, nil)
+
+	l.ClearEager()
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+	_, err = sl.Use(l, LoggerSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	ctx := context.WithValue(context.Background(), userKey{}, "alice")
+	user, err := sl.UseContext(ctx, l, userSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, user, "alice")
+}
+
+func TestResetAllCleanup(t *testing.T) {
+	l := sl.New()
+
+	cleanups := 0
+	sl.ProvideFuncCleanup(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	}, func(*Config) error {
+		cleanups++
+		return nil
+	})
+
+	sl.MustInvoke(l, ConfigSlot)
+	assert.NilError(t, l.ResetAll())
+	assert.Equal(t, cleanups, 1)
+
+	sl.MustInvoke(l, ConfigSlot)
+	assert.NilError(t, l.Close())
+	assert.Equal(t, cleanups, 2)
+}
+
+func TestResetAutoCloser(t *testing.T) {
+	provide := func(autoCloser bool) (*sl.ServiceLocator, *int) {
+		l := sl.New()
+		l.SetAutoCloser(autoCloser)
+
+		closed := 0
+		sl.Provide(l, ConfigSlot, &Config{Foo: "v1"})
+		sl.ProvideFunc(l, closerSlotA, func(l *sl.ServiceLocator) (closerFunc, error) {
+			sl.MustInvoke(l, ConfigSlot)
+			return func() error {
+				closed++
+				return nil
+			}, nil
+		})
+
+		return l, &closed
+	}
+
+	// without the auto-closer callers close the values themselves
+	l, closed := provide(false)
+	sl.MustInvoke(l, closerSlotA)
+	assert.NilError(t, l.ResetAll())
+	sl.MustInvoke(l, closerSlotA)
+	sl.OverrideInvalidate(l, ConfigSlot, &Config{Foo: "v2"})
+	sl.MustInvoke(l, closerSlotA)
+	assert.Equal(t, sl.Reset(l, closerSlotA), true)
+	assert.NilError(t, l.Close())
+	assert.Equal(t, *closed, 0)
+
+	// with the auto-closer each instance is closed exactly once
+	l, closed = provide(true)
+	sl.MustInvoke(l, closerSlotA)
+	assert.Equal(t, sl.Reset(l, closerSlotA), true)
+	assert.Equal(t, *closed, 1)
+
+	sl.MustInvoke(l, closerSlotA)
+	assert.DeepEqual(t, sl.ResetCascade(l, ConfigSlot), []string{"sl_test.closerFunc"})
+	assert.Equal(t, *closed, 2)
+
+	sl.MustInvoke(l, closerSlotA)
+	assert.NilError(t, l.Close())
+	assert.Equal(t, *closed, 3)
+}
@@ -0,0 +1,139 @@
+package sl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ShutdownTimeout is how long [Run] waits for [ServiceLocator.Close] before
+// giving up on the shutdown.
+var ShutdownTimeout = 30 * time.Second
+
+// Starter is implemented by services that have to be started once the
+// application is wired, for example an HTTP server, see
+// [ServiceLocator.Start]. Start must return once the service is running, the
+// context is done when the application shuts down.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Validate configures the given slots, usually the entrypoints of the
+// application, so that missing or broken services are found at startup
+// instead of on first use. Each key must be a slot, all keys are validated
+// even if some fail and the returned error joins all their errors.
+func (l *ServiceLocator) Validate(keys ...any) error {
+	errs := []error{}
+	for _, key := range keys {
+		if err := l.validate(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate configures the slot with the given key
+func (l *ServiceLocator) validate(key any) error {
+	typeName, ok := slotKeyTypeName(key)
+	if !ok {
+		return fmt.Errorf(`key of type %T is not a slot`, key)
+	}
+
+	_, _, ok, err := l.useEntry(key, typeName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf(`%w for type %s`, ErrSlotNotFound, typeName)
+	}
+
+	return nil
+}
+
+// Start calls [Starter.Start] on the values of this locator (not including
+// its parent scopes) implementing [Starter]: first the eagerly provided ones
+// in registration order and then the configured lazy ones in configuration
+// order, so services are started after their dependencies. This stops at the
+// first error.
+func (l *ServiceLocator) Start(ctx context.Context) error {
+	l.mu.Lock()
+	entries := []*slotEntry{}
+	seen := map[*slotEntry]bool{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if s.configureFunc == nil && s.extractFunc == nil {
+			entries = append(entries, s)
+			seen[s] = true
+		}
+	}
+	for _, s := range l.configuredEntries() {
+		if !seen[s] {
+			entries = append(entries, s)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, s := range entries {
+		starter, ok := s.value.(Starter)
+		if !ok {
+			continue
+		}
+
+		l.logf(`[slot: %s] starting`, s.typeName)
+
+		if err := starter.Start(ctx); err != nil {
+			return fmt.Errorf(`starting %s: %w`, s.typeName, err)
+		}
+	}
+
+	return nil
+}
+
+// Run is the skeleton of a typical main function: it validates the given
+// entrypoint slots (see [ServiceLocator.Validate]), starts the services (see
+// [ServiceLocator.Start]), waits for "ctx" to be done and then closes the
+// locator (see [ServiceLocator.Close]) waiting at most [ShutdownTimeout]. For
+// example
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//
+//	if err := sl.Run(ctx, l, ServerSlot); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// If validating or starting fails the locator is closed right away. The
+// returned error joins the startup and shutdown errors, the cancellation of
+// "ctx" is not an error.
+func Run(ctx context.Context, l *ServiceLocator, keys ...any) error {
+	err := l.Validate(keys...)
+	if err == nil {
+		err = l.Start(ctx)
+	}
+	if err == nil {
+		<-ctx.Done()
+	}
+
+	return errors.Join(err, closeWithTimeout(l, ShutdownTimeout))
+}
+
+// closeWithTimeout closes "l" returning an error if it takes longer than
+// "timeout", the cleanups keep running in the background in that case.
+func closeWithTimeout(l *ServiceLocator, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Close()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf(`shutdown timed out: %w`, context.DeadlineExceeded)
+	}
+}
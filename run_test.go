@@ -0,0 +1,128 @@
+package sl_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+// fakeServer records its lifecycle
+type fakeServer struct {
+	events   *[]string
+	name     string
+	startErr error
+}
+
+func (s *fakeServer) Start(ctx context.Context) error {
+	*s.events = append(*s.events, "start "+s.name)
+	return s.startErr
+}
+
+func (s *fakeServer) Close() error {
+	*s.events = append(*s.events, "close "+s.name)
+	return nil
+}
+
+var (
+	serverSlot = sl.NewSlot[*fakeServer]()
+	workerSlot = sl.NewSlot[*fakeServer]()
+)
+
+func TestRun(t *testing.T) {
+	events := []string{}
+
+	l := sl.New()
+	l.SetAutoCloser(true)
+
+	sl.ProvideFunc(l, workerSlot, func(l *sl.ServiceLocator) (*fakeServer, error) {
+		return &fakeServer{events: &events, name: "worker"}, nil
+	})
+	sl.ProvideFunc(l, serverSlot, func(l *sl.ServiceLocator) (*fakeServer, error) {
+		sl.MustInvoke(l, workerSlot)
+		return &fakeServer{events: &events, name: "server"}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- sl.Run(ctx, l, serverSlot)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	assert.NilError(t, <-done)
+	assert.DeepEqual(t, events, []string{
+		"start worker",
+		"start server",
+		"close server",
+		"close worker",
+	})
+}
+
+func TestStartEager(t *testing.T) {
+	events := []string{}
+
+	l := sl.New()
+
+	sl.Provide(l, workerSlot, &fakeServer{events: &events, name: "worker"})
+	sl.ProvideFunc(l, serverSlot, func(l *sl.ServiceLocator) (*fakeServer, error) {
+		sl.MustInvoke(l, workerSlot)
+		return &fakeServer{events: &events, name: "server"}, nil
+	})
+
+	assert.NilError(t, l.Validate(serverSlot))
+	assert.NilError(t, l.Start(context.Background()))
+	assert.DeepEqual(t, events, []string{"start worker", "start server"})
+}
+
+func TestRunStartupError(t *testing.T) {
+	events := []string{}
+
+	l := sl.New()
+	l.SetAutoCloser(true)
+
+	errStart := errors.New("address already in use")
+	sl.ProvideFunc(l, workerSlot, func(l *sl.ServiceLocator) (*fakeServer, error) {
+		return &fakeServer{events: &events, name: "worker"}, nil
+	})
+	sl.ProvideFunc(l, serverSlot, func(l *sl.ServiceLocator) (*fakeServer, error) {
+		sl.MustInvoke(l, workerSlot)
+		return &fakeServer{events: &events, name: "server", startErr: errStart}, nil
+	})
+
+	err := sl.Run(context.Background(), l, serverSlot)
+	assert.Assert(t, errors.Is(err, errStart))
+	assert.DeepEqual(t, events, []string{
+		"start worker",
+		"start server",
+		"close server",
+		"close worker",
+	})
+
+	err = sl.Run(context.Background(), sl.New(), serverSlot, "server")
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+	assert.ErrorContains(t, err, "key of type string is not a slot")
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { sl.ShutdownTimeout = timeout }(sl.ShutdownTimeout)
+	sl.ShutdownTimeout = 10 * time.Millisecond
+
+	l := sl.New()
+	l.RegisterCleanup("slow", func() error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := sl.Run(ctx, l)
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+}
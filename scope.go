@@ -0,0 +1,191 @@
+package sl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Scope creates a child [ServiceLocator] of "l". Slots and hooks not found in
+// the child are searched in its parent scopes, while new values provided in
+// the child are not visible to the parent.
+//
+// Lazy slots are always configured and cached by the locator owning them, so
+// resolving a slot of the parent from many child scopes only creates one
+// instance. The [LocatorSlot] of a child scope resolves to the child itself.
+func (l *ServiceLocator) Scope() *ServiceLocator {
+	root := &ServiceLocator{locatorState: l.locatorState}
+	return newLocator(root)
+}
+
+// WithValue creates a child scope of "l" (see [ServiceLocator.Scope]) carrying
+// the value "val" for "key", this can be read with [ScopeValue] from the scope
+// and from its children but not from "l". This is useful to tag scopes, for
+// example with the id of the current tenant.
+//
+// Lazy slots are configured by the locator owning them, so only the slots
+// provided with [ProvideScoped] (or provided in the scope itself) can read the
+// values of the scope while being configured.
+func (l *ServiceLocator) WithValue(key, val any) *ServiceLocator {
+	scope := l.Scope()
+	scope.values = map[any]any{key: val}
+
+	return scope
+}
+
+// ScopeValue returns the value for "key" of the nearest scope created with
+// [ServiceLocator.WithValue], false if there is none or it is not a "T".
+func ScopeValue[T any](l *ServiceLocator, key any) (T, bool) {
+	for current := l; current != nil; current = current.parent {
+		if v, ok := current.values[key]; ok {
+			t, ok := v.(T)
+			return t, ok
+		}
+	}
+
+	return zero[T](), false
+}
+
+// Derive creates a copy of "l" sharing its slots but not its registrations:
+// values provided or overridden in the copy are not visible to "l" and the
+// other way around. Unlike [ServiceLocator.Scope], that keeps looking up its
+// parent, this is a snapshot of the slots and hooks of "l" at the time of the
+// call.
+//
+// Slots are shared by reference, so already configured instances are reused
+// and lazy slots resolved from the copy are cached back to the shared slot
+// (and visible to "l" too). These are configured against the copy, so their
+// dependencies are resolved from it. The copy only closes or resets the
+// instances that got configured through it (see [ServiceLocator.Close]).
+func (l *ServiceLocator) Derive() *ServiceLocator {
+	d := newLocator(nil)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	d.mu = l.mu
+	d.parent = l.parent
+	d.settings = l.settings
+	d.values = l.values
+	for key := range l.barrier {
+		if d.barrier == nil {
+			d.barrier = map[any]bool{}
+		}
+		d.barrier[key] = true
+	}
+	d.logger.Store(l.logger.Load())
+	d.subscribers = append(d.subscribers, l.subscribers...)
+
+	for _, key := range l.slotKeys {
+		d.providers[key] = l.providers[key]
+		d.slotKeys = append(d.slotKeys, key)
+	}
+
+	for key, members := range l.groups {
+		d.groups[key] = append([]*slotEntry{}, members...)
+	}
+
+	if l.keyed != nil {
+		d.keyed = make(map[any]*keyedInstances, len(l.keyed))
+		for slotKey, instances := range l.keyed {
+			entries := make(map[any]*slotEntry, len(instances.entries))
+			for key, entry := range instances.entries {
+				entries[key] = entry
+			}
+
+			d.keyed[slotKey] = &keyedInstances{
+				keys:    append([]any{}, instances.keys...),
+				entries: entries,
+			}
+		}
+	}
+
+	for key, h := range l.hooks {
+		d.hooks[key] = &hookEntry{
+			typeName:    h.typeName,
+			payloadType: h.payloadType,
+			listeners:   append([]func(*ServiceLocator, any) error{}, h.listeners...),
+			inherit:     h.inherit,
+		}
+		if h.alive != nil {
+			d.hooks[key].alive = append([]func() bool{}, h.alive...)
+		}
+	}
+
+	if l.typeBindings != nil {
+		d.typeBindings = make(map[reflect.Type]typeBinding, len(l.typeBindings))
+		for t, binding := range l.typeBindings {
+			d.typeBindings[t] = binding
+		}
+	}
+
+	return d
+}
+
+// UseHookScoped is like [UseHook] but each listener is called with its own
+// fresh child scope of "l" (see [ServiceLocator.Scope]), so listeners don't
+// share any value provided during the dispatch. If "scopeInit" is not nil it
+// is called on each scope before passing it to the listener.
+//
+// Listeners are called in order and the dispatch stops at the first error or
+// at [ErrStopPropagation], like for [UseHook].
+func UseHookScoped[T any](l *ServiceLocator, hookKey hook[T], value T, scopeInit func(*ServiceLocator)) error {
+	typeName, listeners, ok, err := l.hookListeners(hookKey, payloadTypeOf[T]())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf(`no injected hooks for hook of type %s`, getTypeName[T]())
+	}
+
+	l.logf(`[hook: %s] calling scoped hook with value of type %T`, typeName, value)
+	l.emit(Event{Kind: EventHookDispatch, TypeName: typeName})
+	for _, hookFunc := range listeners {
+		scope := l.Scope()
+		if scopeInit != nil {
+			scopeInit(scope)
+		}
+
+		if err := hookFunc(scope, value); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				return nil
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProvideScoped is like [ProvideFunc] but the value is created and cached once
+// per scope: resolving the slot from a child scope creates an instance owned by
+// that child (configured against the child itself) and repeatedly using the
+// slot from the same scope returns the same instance. Resolving the slot from
+// the locator where it was provided creates its own instance.
+//
+// Instances belong to the scope that created them, so they are reset or closed
+// only by the same scope (for example with [ServiceLocator.ResetAll]).
+func ProvideScoped[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error)) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject scoped lazy provider`, typeName)
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:      typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+		scoped:        true,
+		cleanupFunc:   l.autoCloseFunc(),
+	})
+}
+
+// scopedCopy returns a new unconfigured entry with the same provider as this
+// scoped slot entry.
+func (s *slotEntry) scopedCopy() *slotEntry {
+	return &slotEntry{
+		typeName:      s.typeName,
+		configureFunc: s.configureFunc,
+		scoped:        true,
+		cleanupFunc:   s.cleanupFunc,
+		source:        s.source,
+	}
+}
@@ -0,0 +1,189 @@
+package sl_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestScope(t *testing.T) {
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	scope := l.Scope()
+	sl.Provide(scope, ExampleServiceSlot, &ExampleService{Bar: "bar"})
+
+	assert.Equal(t, sl.MustUse(scope, ConfigSlot).Foo, "foo")
+	assert.Equal(t, sl.MustUse(scope, ExampleServiceSlot).Bar, "bar")
+	assert.Equal(t, sl.MustUse(scope, sl.LocatorSlot), scope)
+
+	_, err := sl.Use(l, ExampleServiceSlot)
+	assert.ErrorContains(t, err, "no injected value")
+}
+
+func TestUseHookScoped(t *testing.T) {
+	requestSlot := sl.NewSlot[int]()
+	requestHook := sl.NewHook[string]()
+
+	l := sl.New()
+
+	seen := []int{}
+	listener := func(l *sl.ServiceLocator, path string) error {
+		if _, err := sl.Use(l, requestSlot); err == nil {
+			t.Fatal("request slot leaked from a previous listener")
+		}
+
+		sl.Provide(l, requestSlot, len(seen))
+		seen = append(seen, sl.MustUse(l, requestSlot))
+		return nil
+	}
+
+	sl.ProvideHook(l, requestHook, listener, listener, listener)
+
+	inits := 0
+	err := sl.UseHookScoped(l, requestHook, "/", func(scope *sl.ServiceLocator) {
+		inits++
+	})
+
+	assert.NilError(t, err)
+	assert.Equal(t, inits, 3)
+	assert.DeepEqual(t, seen, []int{0, 1, 2})
+}
+
+func TestProvideScoped(t *testing.T) {
+	requestIDSlot := sl.NewSlot[int]()
+	requestSlot := sl.NewSlot[*Config]()
+
+	l := sl.New()
+	sl.Provide(l, requestIDSlot, 0)
+
+	created := 0
+	sl.ProvideScoped(l, requestSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		created++
+		return &Config{Foo: fmt.Sprint(sl.MustUse(l, requestIDSlot))}, nil
+	})
+
+	scope1 := l.Scope()
+	sl.Provide(scope1, requestIDSlot, 1)
+	scope2 := l.Scope()
+	sl.Provide(scope2, requestIDSlot, 2)
+
+	config1 := sl.MustUse(scope1, requestSlot)
+	config2 := sl.MustUse(scope2, requestSlot)
+	config0 := sl.MustUse(l, requestSlot)
+
+	assert.Equal(t, config1.Foo, "1")
+	assert.Equal(t, config2.Foo, "2")
+	assert.Equal(t, config0.Foo, "0")
+
+	assert.Equal(t, sl.MustUse(scope1, requestSlot), config1)
+	assert.Equal(t, sl.MustUse(scope2, requestSlot), config2)
+	assert.Equal(t, sl.MustUse(l, requestSlot), config0)
+	assert.Equal(t, created, 3)
+}
+
+func TestDerive(t *testing.T) {
+	l := sl.New()
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	created := 0
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		created++
+		return &ExampleService{Bar: sl.MustUse(l, ConfigSlot).Foo}, nil
+	})
+
+	derived := l.Derive()
+	assert.Equal(t, sl.MustUse(derived, ConfigSlot), config)
+	assert.Equal(t, sl.MustUse(derived, sl.LocatorSlot), derived)
+
+	service := sl.MustUse(derived, ExampleServiceSlot)
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot), service)
+	assert.Equal(t, created, 1)
+
+	sl.Provide(derived, ConfigSlot, &Config{Foo: "bar"})
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+
+	sl.Provide(l, LoggerSlot, nil)
+	_, err := sl.Use(derived, LoggerSlot)
+	assert.ErrorContains(t, err, "no injected value")
+}
+
+func TestScopeCachesOnOwner(t *testing.T) {
+	l := sl.New()
+
+	created := 0
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		created++
+		return &Config{}, nil
+	})
+
+	scope1 := l.Scope()
+	scope2 := l.Scope()
+
+	config1 := sl.MustUse(scope1, ConfigSlot)
+	config2 := sl.MustUse(scope2, ConfigSlot)
+
+	assert.Equal(t, created, 1)
+	assert.Equal(t, config1, config2)
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config1)
+	assert.DeepEqual(t, l.UnusedSlots(), []string{})
+	assert.Equal(t, len(scope1.Stats()), 0)
+}
+
+func TestWithValue(t *testing.T) {
+	type tenantKey struct{}
+
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "shared"})
+
+	sl.ProvideScoped(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		tenant, _ := sl.ScopeValue[string](l, tenantKey{})
+		return &ExampleService{Bar: tenant}, nil
+	})
+
+	acme := l.WithValue(tenantKey{}, "acme")
+	globex := l.WithValue(tenantKey{}, "globex")
+
+	assert.Equal(t, sl.MustUse(acme, ExampleServiceSlot).Bar, "acme")
+	assert.Equal(t, sl.MustUse(globex.Scope(), ExampleServiceSlot).Bar, "globex")
+
+	tenant, ok := sl.ScopeValue[string](acme.Scope(), tenantKey{})
+	assert.Equal(t, ok, true)
+	assert.Equal(t, tenant, "acme")
+
+	_, ok = sl.ScopeValue[string](l, tenantKey{})
+	assert.Equal(t, ok, false)
+
+	_, ok = sl.ScopeValue[int](acme, tenantKey{})
+	assert.Equal(t, ok, false)
+}
+
+func TestSealScope(t *testing.T) {
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	before := l.Scope()
+	l.Seal()
+	assert.Assert(t, l.Sealed())
+
+	scope := l.Scope()
+	assert.Assert(t, !scope.Sealed())
+
+	sl.Provide(scope, ConfigSlot, &Config{Foo: "scope"})
+	sl.Provide(before, ConfigSlot, &Config{Foo: "before"})
+	assert.Equal(t, sl.MustUse(scope, ConfigSlot).Foo, "scope")
+	assert.Equal(t, sl.MustUse(before, ConfigSlot).Foo, "before")
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "bar"})
+	sl.ProvideMulti(l, ConfigSlot, &Config{Foo: "member"})
+	sl.ProvideKeyed(l, ConfigSlot, "key", &Config{Foo: "keyed"})
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "foo")
+	assert.DeepEqual(t, sl.UseAllOptional(l, ConfigSlot), []*Config{})
+
+	_, err := sl.UseKeyed(l, ConfigSlot, "key")
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
@@ -0,0 +1,26 @@
+package sl
+
+// Seal forbids providing slots in this locator from now on, this is useful to
+// freeze the wiring of an application once it is bootstrapped. Providing a
+// slot (or a member of a group or a keyed instance) in a sealed locator is
+// logged and ignored, like for [LocatorSlot].
+//
+// Sealing only applies to this locator: child scopes (including the ones
+// created before sealing) and copies made with [ServiceLocator.Derive] stay
+// mutable, as scopes are the intended place for runtime provides. Lazy slots
+// of a sealed locator are still configured on use, and hooks can still be
+// provided and subscribed to.
+func (l *ServiceLocator) Seal() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sealed = true
+}
+
+// Sealed tells if this locator got sealed with [ServiceLocator.Seal]
+func (l *ServiceLocator) Sealed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.sealed
+}
@@ -0,0 +1,236 @@
+package sl
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// settings are the options of a locator changed with its various Set methods,
+// child scopes start with a copy of the settings of their parent.
+type settings struct {
+	// assertNonNilOnUse, see [ServiceLocator.SetAssertNonNilOnUse]
+	assertNonNilOnUse bool
+
+	// goroutineID is the only goroutine allowed to use the locator when not
+	// zero, see [ServiceLocator.SetSingleGoroutine]
+	goroutineID uint64
+
+	// captureSource, see [ServiceLocator.SetCaptureSource]
+	captureSource bool
+
+	// autoCloser, see [ServiceLocator.SetAutoCloser]
+	autoCloser bool
+
+	// interceptors, see [ServiceLocator.AddProvideInterceptor]
+	interceptors []func(typeName string, value any) any
+
+	// deterministic, see [ServiceLocator.SetDeterministic]
+	deterministic bool
+
+	// warmUpConcurrency, see [ServiceLocator.SetWarmUpConcurrency]
+	warmUpConcurrency int
+
+	// slowThreshold, see [ServiceLocator.SetSlowThreshold]
+	slowThreshold time.Duration
+
+	// onHookListener, see [ServiceLocator.OnHookListener]
+	onHookListener func(hookType string, index int, dur time.Duration, err error)
+}
+
+// SetAssertNonNilOnUse enables or disables checking that values resolved by
+// [Use] and its variations are not nil pointers or interfaces, when enabled
+// resolving a nil value returns an error (and the Must functions panic). This
+// catches lazy constructors that mistakenly return "nil, nil".
+func (l *ServiceLocator) SetAssertNonNilOnUse(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.assertNonNilOnUse = enabled
+}
+
+// SetSingleGoroutine enables or disables a debug mode where the locator is
+// bound to the goroutine calling this method: using slots from any other
+// goroutine returns an error (and the Must functions panic) while providing
+// slots logs a warning. Child scopes created afterwards are bound to the same
+// goroutine.
+//
+// The locator is always safe for concurrent use, this is only a diagnostic aid
+// for applications assuming their wiring to be single-threaded. Note that
+// this also rejects uses from listeners called by [UseHookConcurrent], while
+// [ServiceLocator.WarmUp] configures the slots on the bound goroutine.
+func (l *ServiceLocator) SetSingleGoroutine(enabled bool) {
+	id := uint64(0)
+	if enabled {
+		id = currentGoroutineID()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.goroutineID = id
+}
+
+// SetAutoCloser enables or disables closing values implementing [io.Closer]
+// with [ServiceLocator.Close], as if they were provided with a cleanup calling
+// their Close method. This only applies to slots provided with [Provide],
+// [ProvideFunc] (and similar functions like [UseOrProvide] and
+// [ProvideScoped]) after enabling it and is disabled by default, so values
+// already closed by hand are not closed twice.
+//
+// Eagerly provided closers are closed in reverse registration order together
+// with the lazy ones. Values of lazy slots discarded by [Reset] (and similar
+// functions) are closed at that point.
+func (l *ServiceLocator) SetAutoCloser(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.autoCloser = enabled
+}
+
+// SetDeterministic enables or disables a mode for tests where operations that
+// normally run concurrently use a stable order instead, so that their results
+// (like the order of side effects and of joined errors) are reproducible in
+// CI. When enabled
+//
+//   - [ServiceLocator.CloseConcurrent] calls the cleanups of each layer one at
+//     a time in configuration order;
+//   - [UseHookConcurrent] calls the listeners one at a time in order;
+//   - [ServiceLocator.WarmUp] configures the slots one at a time in
+//     registration order.
+//
+// Other operations always use a stable order regardless of this setting, for
+// example [ServiceLocator.Close] uses the reverse configuration order,
+// [UseKeyedAll] the registration order and [Diff] sorts by type name (and
+// hooks sharing a type name by creation order). This applies to child scopes
+// created afterwards and is disabled by default.
+func (l *ServiceLocator) SetDeterministic(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.deterministic = enabled
+}
+
+// SetSlowThreshold makes the locator (and child scopes created afterwards) log
+// a warning with its debug logger when a lazy slot takes longer than "d" to
+// configure, this surfaces unexpectedly slow services (for example waiting on
+// a DNS timeout) without profiling. As for [ServiceLocator.Stats] the duration
+// includes the time spent configuring the dependencies. This is disabled by
+// default, passing zero disables it.
+func (l *ServiceLocator) SetSlowThreshold(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.slowThreshold = d
+}
+
+// SetCaptureSource enables or disables recording the file and line where each
+// slot gets provided, the location is then reported by [ServiceLocator.Stats]
+// and in the errors of lazy slots failing to configure. This helps finding
+// which module provided a surprising implementation, the stack is only
+// inspected when enabled.
+func (l *ServiceLocator) SetCaptureSource(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.captureSource = enabled
+}
+
+// callerSource returns the "file:line" of the first caller outside of this
+// package
+func callerSource() string {
+	pkgPrefix := reflect.TypeOf(symbol{}).PkgPath() + "."
+
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) {
+			return fmt.Sprintf(`%s:%d`, frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// AddProvideInterceptor registers a function called with every value provided
+// with [Provide] and every value created by a lazy slot of this locator (and
+// of child scopes created afterwards), the value returned by the interceptor
+// replaces the original one. Interceptors are called in registration order,
+// each one with the value returned by the previous one. This is useful for
+// test harnesses and instrumentation, for example to wrap all the services of
+// some type.
+//
+// The interceptor must return a value assignable to the type of the slot
+// ("typeName" tells which it is), otherwise using the slot returns an error.
+func (l *ServiceLocator) AddProvideInterceptor(fn func(typeName string, value any) any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// copy to not share the slice with child scopes
+	interceptors := make([]func(string, any) any, 0, len(l.settings.interceptors)+1)
+	interceptors = append(interceptors, l.settings.interceptors...)
+	l.settings.interceptors = append(interceptors, fn)
+}
+
+// intercept calls the provide interceptors on "value", this takes the lock of
+// the locator.
+func (l *ServiceLocator) intercept(typeName string, value any) any {
+	l.mu.Lock()
+	interceptors := l.settings.interceptors
+	l.mu.Unlock()
+
+	for _, fn := range interceptors {
+		value = fn(typeName, value)
+	}
+
+	return value
+}
+
+// checkGoroutine returns an error if the locator is bound to a goroutine
+// other than the current one, the lock of the locator must be held.
+func (l *ServiceLocator) checkGoroutine() error {
+	if l.settings.goroutineID == 0 {
+		return nil
+	}
+
+	if id := currentGoroutineID(); id != l.settings.goroutineID {
+		return fmt.Errorf(`locator bound to goroutine %d used from goroutine %d`, l.settings.goroutineID, id)
+	}
+
+	return nil
+}
+
+// currentGoroutineID parses the id of the current goroutine from its stack
+// trace, this is slow and should only be used for debugging.
+func currentGoroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	// the stack starts with "goroutine 123 [running]:"
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	buf = buf[:bytes.IndexByte(buf, ' ')]
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// isNil tells if "v" is nil or is a typed nil of a nillable kind
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,199 @@
+package sl_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestSetAssertNonNilOnUse(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return nil, nil
+	})
+	sl.Provide[Greeter](l, GreeterSlot, nil)
+
+	config, err := sl.Use(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Assert(t, config == nil)
+
+	l.SetAssertNonNilOnUse(true)
+
+	_, err = sl.Use(l, ConfigSlot)
+	assert.Error(t, err, "slot of type *sl_test.Config resolved to a nil value")
+
+	_, err = sl.Use(l, GreeterSlot)
+	assert.Error(t, err, "slot of type sl_test.Greeter resolved to a nil value")
+
+	err = l.Recover(func() error {
+		sl.MustUse(l.Scope(), ConfigSlot)
+		return nil
+	})
+	assert.Error(t, err, "slot of type *sl_test.Config resolved to a nil value")
+}
+
+func TestSetSingleGoroutine(t *testing.T) {
+	var buf bytes.Buffer
+	defer sl.SetLogger(log.New(&buf, "", 0))()
+
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	l.SetSingleGoroutine(true)
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.NilError(t, err)
+
+	done := make(chan error)
+	go func() {
+		sl.Provide(l, LoggerSlot, log.Default())
+
+		_, err := sl.Use(l, ConfigSlot)
+		done <- err
+	}()
+
+	assert.ErrorContains(t, <-done, "used from goroutine")
+	assert.Assert(t, bytes.Contains(buf.Bytes(), []byte("[slot: *log.Logger] warning: locator bound to goroutine")))
+
+	l.SetSingleGoroutine(false)
+
+	go func() {
+		_, err := sl.Use(l, ConfigSlot)
+		done <- err
+	}()
+
+	assert.NilError(t, <-done)
+}
+
+func TestSetCaptureSource(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, LoggerSlot, log.Default())
+
+	l.SetCaptureSource(true)
+
+	sl.ProvideFuncSimple(l, ConfigSlot, func() (*Config, error) {
+		return nil, errors.New("boom")
+	})
+	_, _, line, _ := runtime.Caller(0)
+
+	stats := l.Stats()
+	assert.Equal(t, stats[0].Source, "")
+	assert.Assert(t, strings.HasSuffix(stats[1].Source, fmt.Sprintf("settings_test.go:%d", line-3)), stats[1].Source)
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.ErrorContains(t, err, fmt.Sprintf("configuring *sl_test.Config (provided at %s): boom", stats[1].Source))
+}
+
+func TestAddProvideInterceptor(t *testing.T) {
+	l := sl.New()
+
+	seen := []string{}
+	l.AddProvideInterceptor(func(typeName string, value any) any {
+		seen = append(seen, typeName)
+		return value
+	})
+	l.AddProvideInterceptor(func(typeName string, value any) any {
+		if config, ok := value.(*Config); ok {
+			return &Config{Foo: "intercepted " + config.Foo}
+		}
+
+		return value
+	})
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	assert.Equal(t, config.Foo, "intercepted foo")
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), config)
+
+	scope := l.Scope()
+	sl.ProvideFunc(scope, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{Bar: sl.MustUse(l, ConfigSlot).Foo}, nil
+	})
+	assert.Equal(t, sl.MustUse(scope, ExampleServiceSlot).Bar, "intercepted foo")
+	assert.DeepEqual(t, seen, []string{"*sl_test.Config", "*sl_test.ExampleService"})
+
+	l.AddProvideInterceptor(func(typeName string, value any) any {
+		return "not a logger"
+	})
+	sl.ProvideFunc(l, LoggerSlot, func(l *sl.ServiceLocator) (*log.Logger, error) {
+		return log.Default(), nil
+	})
+	_, err := sl.Use(l, LoggerSlot)
+	assert.Error(t, err, "slot expects *log.Logger but provider returned string")
+}
+
+func TestSetSlowThreshold(t *testing.T) {
+	l := sl.New()
+
+	var buf bytes.Buffer
+	defer l.SetLogger(log.New(&buf, "", 0))()
+
+	slowSlot := sl.NewSlot[string]()
+	sl.ProvideFunc(l, slowSlot, func(l *sl.ServiceLocator) (string, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "slow", nil
+	})
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{Bar: sl.MustUse(l, ConfigSlot).Foo}, nil
+	})
+
+	sl.MustInvoke(l, slowSlot)
+	assert.Assert(t, !strings.Contains(buf.String(), "warning"))
+
+	assert.NilError(t, l.ResetAll())
+	l.SetSlowThreshold(10 * time.Millisecond)
+
+	sl.MustInvoke(l, slowSlot)
+	sl.MustInvoke(l, ExampleServiceSlot)
+	assert.Assert(t, strings.Contains(buf.String(), "[slot: string] warning: slow configuration took "))
+	assert.Assert(t, !strings.Contains(buf.String(), "[slot: *sl_test.ExampleService] warning"))
+}
+
+func TestSetDeterministic(t *testing.T) {
+	l := sl.New()
+	l.SetDeterministic(true)
+
+	// no synchronization is needed as nothing runs concurrently
+	called := []int{}
+	listener := func(i int) sl.Hook[string] {
+		return func(l *sl.ServiceLocator, s string) error {
+			called = append(called, i)
+			return nil
+		}
+	}
+
+	exampleHook := sl.NewHook[string]()
+	sl.ProvideHook(l, exampleHook, listener(0), listener(1), listener(2))
+
+	_, err := sl.UseHookConcurrent(l, exampleHook, "foo")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, called, []int{0, 1, 2})
+
+	closed := []string{}
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+
+		nameSlot := sl.NewSlot[string]()
+		sl.ProvideFuncCleanup(l, nameSlot, func(l *sl.ServiceLocator) (string, error) {
+			return name, nil
+		}, func(string) error {
+			closed = append(closed, name)
+			return nil
+		})
+		sl.MustInvoke(l, nameSlot)
+	}
+
+	assert.NilError(t, l.CloseConcurrent(context.Background()))
+	assert.DeepEqual(t, closed, []string{"a", "b", "c"})
+}
@@ -22,14 +22,61 @@
 //   - a package can also just provide a slot with some value. This is useful
 //     for using the ServiceLocator to easily pass around values, effectively
 //     threating slots just as dynamically scoped variables.
+//
+// The package requires Go 1.20, except for [SubscribeWeak] that is only
+// available when building with Go 1.24 or later.
 package sl
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrSlotNotFound is returned (wrapped) when resolving a slot with no
+// injected value.
+var ErrSlotNotFound = errors.New(`no injected value`)
+
+// ErrDependencyCycle is returned (wrapped) when a lazy slot is used while
+// configuring itself, directly or through other slots and hook listeners. This
+// is also detected when the slot is used through a locator captured before
+// the configuration, as long as it happens on the same goroutine.
+var ErrDependencyCycle = errors.New(`dependency cycle`)
+
+// ErrStopPropagation can be returned by a hook listener to stop calling the
+// next listeners, the dispatch is then considered successful. See [UseHook].
+var ErrStopPropagation = errors.New(`stop`)
+
+// ErrSlotDisabled is returned (wrapped) when resolving a slot provided with
+// [ProvideFuncIf] whose feature flag is false.
+var ErrSlotDisabled = errors.New(`slot disabled`)
+
+// SlotError is the value the Must functions (like [MustUse]) panic with, see
+// [ServiceLocator.Recover].
+type SlotError struct {
+	// TypeName is the type name of the slot or hook that failed
+	TypeName string
+
+	// Err is the error returned by the non panicking function
+	Err error
+}
+
+func (e *SlotError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SlotError) Unwrap() error {
+	return e.Err
+}
+
 func zero[T any]() T {
 	var zero T
 	return zero
@@ -44,16 +91,96 @@ func zero[T any]() T {
 // complex)
 var Logger *log.Logger = log.New(os.Stderr, "[service locator] ", log.Lmsgprefix)
 
+// SetLogger replaces the package [Logger] with "logger" and returns a function
+// that restores the previous one, this is mostly useful in tests like
+//
+//	defer sl.SetLogger(log.New(io.Discard, "", 0))()
+func SetLogger(logger *log.Logger) (restore func()) {
+	previous := Logger
+	Logger = logger
+
+	return func() {
+		Logger = previous
+	}
+}
+
+// SetLogger replaces the debug logger of this locator (and of child scopes
+// created afterwards) with "logger", by default locators use the package
+// [Logger]. Passing nil goes back to the package logger. This returns a
+// function that restores the previous logger.
+func (l *ServiceLocator) SetLogger(logger *log.Logger) (restore func()) {
+	previous := l.logger.Swap(logger)
+
+	return func() {
+		l.logger.Store(previous)
+	}
+}
+
+// WithSilentLogger calls "fn" with the debug logger of this locator replaced
+// by one discarding everything, the previous logger is restored when "fn"
+// returns or panics. This is useful to run a known noisy step, like a warm-up
+// or validation pass.
+//
+// The logger is shared by all goroutines using this locator, so their logs
+// are silenced too while "fn" runs. Child scopes created before the call keep
+// their logger.
+func (l *ServiceLocator) WithSilentLogger(fn func()) {
+	defer l.SetLogger(log.New(io.Discard, "", 0))()
+
+	fn()
+}
+
+// debugLogger returns the logger of this locator or the package [Logger] if
+// it has none
+func (l *ServiceLocator) debugLogger() *log.Logger {
+	if logger := l.logger.Load(); logger != nil {
+		return logger
+	}
+
+	return Logger
+}
+
+// logf prints a line with the debug logger of this locator
+func (l *ServiceLocator) logf(format string, v ...any) {
+	l.debugLogger().Printf(format, v...)
+}
+
+// symbol is the type pointed by slots and hooks. This must not be zero sized
+// as pointers to distinct zero sized variables may be equal.
+type symbol struct {
+	// label is the optional name of a slot, see [NewSlotNamed]
+	label string
+
+	// seq is the creation order of a slot or hook, this gives a stable order
+	// to keys sharing the same type name (see [Diff] and [ProvideFuncAll])
+	seq uint64
+}
+
+// symbolCount counts the slots and hooks created so far
+var symbolCount atomic.Uint64
+
+// keySeq returns the creation order of a slot or hook key, zero for other
+// values
+func keySeq(key any) uint64 {
+	v := reflect.ValueOf(key)
+	symbolType := reflect.TypeOf((*symbol)(nil))
+	if !v.IsValid() || !v.Type().ConvertibleTo(symbolType) {
+		return 0
+	}
+
+	return v.Convert(symbolType).Interface().(*symbol).seq
+}
+
 // slot is just a "typed" unique "symbol"
 //
 // This must be defined like so and not for example "struct{ typeName string }"
 // because we might want to have more slots for the same type.
-type slot[T any] *struct{}
+type slot[T any] *symbol
 
 // hook is just a "typed" unique "symbol"
 //
 // See [slot] for more information about this type
-type hook[T any] *struct{}
+type hook[T any] *symbol
 
 type Hook[T any] func(*ServiceLocator, T) error
 
@@ -63,7 +190,47 @@ type Hook[T any] func(*ServiceLocator, T) error
 // This then lets you attach a service instance of type "T" for this slot to a
 // [ServiceLocator] object.
 func NewSlot[T any]() slot[T] {
-	return slot[T](new(struct{}))
+	return slot[T](&symbol{seq: symbolCount.Add(1)})
+}
+
+// NewSlotNamed is like [NewSlot] but attaches a human readable label to the
+// slot, the label is used together with the type name in logs, errors and
+// reports to tell apart slots of the same type. Slots are still unique even
+// if they have the same label.
+func NewSlotNamed[T any](label string) slot[T] {
+	return slot[T](&symbol{label: label, seq: symbolCount.Add(1)})
+}
+
+// slotName returns the name of a slot used in logs and errors, this is the
+// type name of its values qualified by the label of the slot if any
+func slotName[T any](slotKey slot[T]) string {
+	if label := (*symbol)(slotKey).label; label != "" {
+		return fmt.Sprintf(`%q (%s)`, label, getTypeName[T]())
+	}
+
+	return getTypeName[T]()
+}
+
+// notFoundError returns the error for a slot with no injected value
+func notFoundError[T any](slotKey slot[T]) error {
+	if (*symbol)(slotKey).label != "" {
+		return fmt.Errorf(`%w for %s`, ErrSlotNotFound, slotName(slotKey))
+	}
+
+	return fmt.Errorf(`%w for type %s`, ErrSlotNotFound, getTypeName[T]())
+}
+
+// NewSlotWithDefault creates a new slot like [NewSlot] and also returns a
+// function that provides "value" as the default for this slot in a locator.
+// This lets a package declare a slot together with its default value
+//
+//	var Slot, ProvideDefault = sl.NewSlotWithDefault[Clock](SystemClock{})
+func NewSlotWithDefault[T any](value T) (slot[T], func(*ServiceLocator)) {
+	slotKey := NewSlot[T]()
+
+	return slotKey, func(l *ServiceLocator) {
+		Provide(l, slotKey, value)
+	}
 }
 
 // NewHook is the only way to create instances of the hook type. Each instance
@@ -71,7 +238,7 @@ func NewSlot[T any]() slot[T] {
 //
 // This lets you have a service dispatch an hook with a message of type "T".
 func NewHook[T any]() hook[T] {
-	return hook[T](new(struct{}))
+	return hook[T](&symbol{seq: symbolCount.Add(1)})
 }
 
 // slotEntry represents a service that can lazily configured
@@ -89,33 +256,311 @@ type slotEntry struct {
 	// configured tells if this slot is already configured
 	configured bool
 
+	// configureMu is held while configuring this slot so that concurrent uses
+	// wait for a single configuration, this is separate from the lock of the
+	// locator so slow constructors don't block other slots.
+	configureMu sync.Mutex
+
+	// configuringGoroutine is the goroutine holding "configureMu", zero when
+	// the slot isn't being configured
+	configuringGoroutine uint64
+
+	// scoped tells if this slot should be configured once per scope, see
+	// [ProvideScoped]
+	scoped bool
+
+	// extractFunc is set for slots derived from the context of each call, see
+	// [ProvideFromContext]
+	extractFunc func(context.Context) (any, error)
+
+	// asyncDone is closed when the background configuration of this slot
+	// ends, see [ProvideFuncEagerAsync]
+	asyncDone chan struct{}
+
+	// asyncErr is the error of the background configuration, this can be read
+	// only after "asyncDone" is closed
+	asyncErr error
+
+	// source is where the slot got provided, see
+	// [ServiceLocator.SetCaptureSource]
+	source string
+
+	// module is the name of the module that provided the slot, see
+	// [ProvideBy]
+	module string
+
+	// cleanupFunc is called by [ServiceLocator.Close] on the value of this
+	// slot if configured
+	cleanupFunc func(any) error
+
+	// slotKey is the key this entry got provided for, this is used to close
+	// the value of a slot once even if provided many times (nil for entries
+	// not provided for a slot)
+	slotKey any
+
+	// groupPriority orders the members of a group, see
+	// [ProvideMultiWithPriority]
+	groupPriority int
+
+	// shutdownPriority orders the cleanups called by [ServiceLocator.Close],
+	// see [ProvideFuncWithShutdownPriority]
+	shutdownPriority int
+
+	// flushBefore has the keys of the slots that must be closed after this
+	// one by [ServiceLocator.Close], see [ProvideFuncFlushBefore]
+	flushBefore []any
+
 	// value for this slot
 	value any
+
+	// dependencies are the slots resolved by "configureFunc" the last time
+	// this slot got configured
+	dependencies []*slotEntry
+
+	// dependents are the slots that resolved this slot while configuring
+	// themselves
+	dependents []*slotEntry
+
+	// configureStart is when the last configuration of this slot started
+	configureStart time.Time
+
+	// configureDuration is how long the last configuration of this slot took,
+	// this includes the time spent configuring its dependencies
+	configureDuration time.Duration
+
+	// latencies are the durations of the last configurations of this slot, at
+	// most [maxLatencySamples]
+	latencies []time.Duration
+
+	// hits counts how many times this slot got used when already configured
+	hits int
+
+	// misses counts how many times this slot had to be configured when used
+	misses int
+
+	// onResolve are the callbacks to call once this slot gets configured, see
+	// [OnResolve]
+	onResolve []func(*ServiceLocator, any) error
+}
+
+// ensureConfigured tries to call configure on this slot entry if not already
+// configured and then returns its value. The lock of "l" must not be held as
+// the configuration can recursively use other slots.
+//
+// Concurrent calls wait for a single configuration, if it fails the next call
+// tries again. A call from the goroutine already configuring the slot returns
+// an error wrapping [ErrDependencyCycle] instead of waiting for itself, this
+// catches cycles through locators captured outside of the "createFunc" (for
+// example a [Getter] on the root locator) that the configure frames don't see.
+func (s *slotEntry) ensureConfigured(l *ServiceLocator) (any, error) {
+	if v, ok := s.cachedValue(l); ok {
+		return v, nil
+	}
+
+	id := currentGoroutineID()
+
+	l.mu.Lock()
+	reentry := s.configuringGoroutine == id
+	l.mu.Unlock()
+
+	if reentry {
+		return nil, fmt.Errorf(`%w: %s used while configuring itself`, ErrDependencyCycle, s.typeName)
+	}
+
+	s.configureMu.Lock()
+	defer s.configureMu.Unlock()
+
+	l.mu.Lock()
+	s.configuringGoroutine = id
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		s.configuringGoroutine = 0
+		l.mu.Unlock()
+	}()
+
+	// check again as another goroutine may have configured the slot
+	if v, ok := s.cachedValue(l); ok {
+		return v, nil
+	}
+
+	l.mu.Lock()
+	s.misses++
+	l.mu.Unlock()
+
+	l.emit(Event{Kind: EventConfigureStart, TypeName: s.typeName})
+
+	start := time.Now()
+	v, err := s.configureFunc(l.dependentView(s))
+	duration := time.Since(start)
+	if err != nil {
+		err = fmt.Errorf(`configuring %s%s: %w`, s.typeName, s.provenance(), err)
+		l.emit(Event{Kind: EventConfigureEnd, TypeName: s.typeName, Duration: duration, Err: err})
+		return nil, err
+	}
+
+	v = l.intercept(s.typeName, v)
+
+	l.logf(`[slot: %s] configured service of type %T`, s.typeName, v)
+
+	l.mu.Lock()
+	s.configureStart = start
+	s.configureDuration = duration
+	s.addLatency(duration)
+	s.configured = true
+	s.value = v
+	l.configureOrder = append(l.configureOrder, s)
+	slowThreshold := l.settings.slowThreshold
+	onResolve := s.onResolve
+	s.onResolve = nil
+	l.mu.Unlock()
+
+	if slowThreshold > 0 && duration > slowThreshold {
+		l.logf(`[slot: %s] warning: slow configuration took %v (threshold %v)`, s.typeName, duration, slowThreshold)
+	}
+
+	if logger := l.debugLogger(); logger.Writer() != io.Discard {
+		l.mu.Lock()
+		dependencies := make([]string, len(s.dependencies))
+		for i, dep := range s.dependencies {
+			dependencies[i] = dep.typeName
+		}
+		l.mu.Unlock()
+
+		logger.Printf(`[slot: %s] resolved dependencies: [%s]`, s.typeName, strings.Join(dependencies, ", "))
+	}
+
+	l.emit(Event{Kind: EventConfigureEnd, TypeName: s.typeName, Duration: duration})
+
+	errs := []error{}
+	for _, fn := range onResolve {
+		if err := fn(l, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf(`on resolve of %s: %w`, s.typeName, err)
+	}
+
+	return v, nil
+}
+
+// provenance describes who provided this slot for error messages, this is
+// empty if neither the module nor the source are known
+func (s *slotEntry) provenance() string {
+	switch {
+	case s.module != "" && s.source != "":
+		return fmt.Sprintf(` (provided by %q at %s)`, s.module, s.source)
+	case s.module != "":
+		return fmt.Sprintf(` (provided by %q)`, s.module)
+	case s.source != "":
+		return fmt.Sprintf(` (provided at %s)`, s.source)
+	default:
+		return ""
+	}
 }
 
-// ensureConfigured tries to call configure on this slot entry if not already configured
-func (s *slotEntry) ensureConfigured(l *ServiceLocator) error {
+// cachedValue returns the value of this slot if already configured, counting
+// the hit. This takes the lock of "l".
+func (s *slotEntry) cachedValue(l *ServiceLocator) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if !s.configured {
-		v, err := s.configureFunc(l)
-		if err != nil {
-			return err
+		return nil, false
+	}
+
+	s.hits++
+	return s.value, true
+}
+
+// addDependency records that "s" resolved "dep" while configuring itself, the
+// lock of the locator must be held.
+func (s *slotEntry) addDependency(dep *slotEntry) {
+	for _, d := range s.dependencies {
+		if d == dep {
+			return
 		}
+	}
 
-		Logger.Printf(`[slot: %s] configured service of type %T`, s.typeName, v)
+	s.dependencies = append(s.dependencies, dep)
+	dep.dependents = append(dep.dependents, s)
+}
 
-		s.configured = true
-		s.value = v
+// clearDependencies forgets all the dependencies recorded for this slot, this
+// is used when the slot gets reset as they will be recorded again on the next
+// configuration. The lock of the locator must be held.
+func (s *slotEntry) clearDependencies() {
+	for _, dep := range s.dependencies {
+		for i, d := range dep.dependents {
+			if d == s {
+				dep.dependents = append(dep.dependents[:i], dep.dependents[i+1:]...)
+				break
+			}
+		}
 	}
 
-	return nil
+	s.dependencies = nil
 }
 
 type hookEntry struct {
 	// typeName is just used for debugging purposes
 	typeName string
 
+	// payloadType is the type of the values the listeners expect
+	payloadType reflect.Type
+
 	// listeners is a list of functions to call when this hook is called
 	listeners []func(*ServiceLocator, any) error
+
+	// alive is parallel to listeners once a weak listener is subscribed, it
+	// tells if each listener is still alive (nil for regular listeners), see
+	// [SubscribeWeak]
+	alive []func() bool
+
+	// inherit tells to also call the listeners of the same hook in the parent
+	// scopes, this is set for hooks created by [Subscribe]
+	inherit bool
+}
+
+// checkPayload returns an error if values of type "payloadType" can't be
+// dispatched to the listeners of this hook. This can only happen for hooks
+// provided with [ProvidePipe] and dispatched with [UseHook] or the other way
+// around.
+func (h *hookEntry) checkPayload(payloadType reflect.Type) error {
+	if h.payloadType == nil || payloadType.AssignableTo(h.payloadType) {
+		return nil
+	}
+
+	switch {
+	case h.payloadType == pipeValueType:
+		return fmt.Errorf(`hook of type %s is a pipe and must be dispatched with PipeHook`, h.typeName)
+	case payloadType == pipeValueType:
+		return fmt.Errorf(`hook of type %s is not a pipe and can't be dispatched with PipeHook`, h.typeName)
+	default:
+		return fmt.Errorf(`hook of type %s expects payloads of type %s, got %s`, h.typeName, h.payloadType, payloadType)
+	}
+}
+
+// prune removes the listeners that are no longer alive
+func (h *hookEntry) prune() {
+	if h.alive == nil {
+		return
+	}
+
+	listeners := []func(*ServiceLocator, any) error{}
+	alive := []func() bool{}
+	for i, listener := range h.listeners {
+		if isAlive := h.alive[i]; isAlive != nil && !isAlive() {
+			continue
+		}
+
+		listeners = append(listeners, listener)
+		alive = append(alive, h.alive[i])
+	}
+
+	h.listeners, h.alive = listeners, alive
 }
 
 // ServiceLocator is the main context passed around to retrive service
@@ -128,117 +573,1119 @@ type hookEntry struct {
 //
 // This is essentially a dictionary of slots and hooks that are them self just
 // uniquely typed symbols.
+//
+// A ServiceLocator can be used from multiple goroutines, a lazy slot used
+// concurrently before being configured is configured only once and the other
+// goroutines wait for it (without blocking the resolution of other slots).
 type ServiceLocator struct {
+	*locatorState
+
+	// dependent is the slot being configured when this locator is the one
+	// passed to a "configureFunc", this is used to record dependency edges
+	// between slots.
+	dependent *slotEntry
+
+	// ctx is the context of the current resolution if done with [UseContext]
+	ctx context.Context
+
+	// configuring are the slots being configured by the current resolution,
+	// this is used to detect dependency cycles
+	configuring *configureFrame
+}
+
+// configureFrame is a slot being configured, linked to the frame of the slot
+// that used it
+type configureFrame struct {
+	entry *slotEntry
+	outer *configureFrame
+}
+
+// cycleError returns an error wrapping [ErrDependencyCycle] if "s" is already
+// being configured by the current resolution, nil otherwise
+func (f *configureFrame) cycleError(s *slotEntry) error {
+	path := []string{s.typeName}
+	for frame := f; frame != nil; frame = frame.outer {
+		path = append(path, frame.entry.typeName)
+
+		if frame.entry == s {
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+
+			return fmt.Errorf(`%w: %s`, ErrDependencyCycle, strings.Join(path, " -> "))
+		}
+	}
+
+	return nil
+}
+
+// locatorState is the actual state of a [ServiceLocator], this is shared by
+// all the views of the same locator.
+type locatorState struct {
+	// mu guards all the state of the locator, this is shared by a locator and
+	// all its child scopes. This is never held while configuring a slot or
+	// calling a listener.
+	mu *sync.Mutex
+
+	// parent is the locator this one was created from with
+	// [ServiceLocator.Scope], this is nil for root locators.
+	parent *ServiceLocator
+
+	settings settings
+
+	// logger is the debug logger of the locator if not nil, see
+	// [ServiceLocator.SetLogger]
+	logger atomic.Pointer[log.Logger]
+
 	providers map[any]*slotEntry
 	hooks     map[any]*hookEntry
+
+	// slotKeys are the keys of "providers" in registration order
+	slotKeys []any
+
+	// groups are the members of the groups provided with [ProvideMulti] and
+	// [ProvideMultiFunc] in registration order
+	groups map[any][]*slotEntry
+
+	// keyed are the instances provided with [ProvideKeyed] and
+	// [ProvideKeyedFunc] by slot key and then by instance key
+	keyed map[any]*keyedInstances
+
+	// sealed tells if providing slots is forbidden, see [ServiceLocator.Seal]
+	sealed bool
+
+	// stubMissing tells to resolve missing slots to their zero value instead
+	// of an error, see [UseStubbed]
+	stubMissing bool
+
+	// barrier are the slots that can't be used until the barrier is
+	// released, see [ServiceLocator.Barrier]
+	barrier map[any]bool
+
+	// values are the values of this scope, see [ServiceLocator.WithValue]
+	values map[any]any
+
+	// recorders are the active recordings of [ServiceLocator.RecordUses]
+	recorders []*useRecorder
+
+	// subscribers are the functions registered with [ServiceLocator.Subscribe]
+	subscribers []func(Event)
+
+	// typeBindings maps types to the slots bound to them, see [BindType]
+	typeBindings map[reflect.Type]typeBinding
+
+	// journal records the changes to "providers" while not nil, see
+	// [ServiceLocator.OverrideScope]
+	journal *[]providerChange
+
+	// configureOrder are the lazy slots of this locator in the order they got
+	// configured (and the eager slots with a cleanup in the order they got
+	// provided), this can contain stale entries for slots that got reset, see
+	// [ServiceLocator.configuredEntries].
+	configureOrder []*slotEntry
+
+	// closed makes [ServiceLocator.Close] and [ServiceLocator.CloseConcurrent]
+	// run only once, "closeErr" is the error returned by the first call.
+	closed   sync.Once
+	closeErr error
 }
 
+// LocatorSlot is pre-registered by [New] in every [ServiceLocator] and
+// resolves to the locator itself. This slot can't be overridden.
+var LocatorSlot = NewSlot[*ServiceLocator]()
+
+// Option configures a new [ServiceLocator], see [New]
+type Option func(*ServiceLocator)
+
 // New creates a new [ServiceLocator] context to pass around in the application.
-func New() *ServiceLocator {
-	return &ServiceLocator{
-		providers: map[any]*slotEntry{},
-		hooks:     map[any]*hookEntry{},
+// The options are applied in order to the new locator.
+func New(opts ...Option) *ServiceLocator {
+	l := newLocator(nil)
+	for _, opt := range opts {
+		opt(l)
 	}
+
+	return l
 }
 
-//
-// Slots
-//
+// WithDefaultLogger is an option for [New] that provides a logger writing to
+// stderr in the given slot, so services can resolve a logger even if the
+// application doesn't wire its own. This is just a default, providing another
+// logger in the slot replaces it.
+func WithDefaultLogger(slotKey slot[*log.Logger]) Option {
+	return func(l *ServiceLocator) {
+		Provide(l, slotKey, log.New(os.Stderr, "", log.LstdFlags))
+	}
+}
 
-// Provide will inject a concrete instance inside the ServiceLocator "l" for
-// the given "slotKey". This should be used for injecting "static" services, for
-// instances whose construction depend on other services you should use the
-// [ProvideFunc] function.
-//
-// This is generic over "T" to check that instances returned by the "createFunc"
-// are compatible with "T" as it can also be an interface.
-func Provide[T any](l *ServiceLocator, slotKey slot[T], value T) T {
-	typeName := getTypeName[T]()
+// newLocator creates a new locator with the given parent, see [New] and
+// [ServiceLocator.Scope].
+func newLocator(parent *ServiceLocator) *ServiceLocator {
+	mu := &sync.Mutex{}
+	settings := settings{}
+	if parent != nil {
+		mu = parent.mu
 
-	Logger.Printf(`[slot: %s] provided value of type %T`, typeName, value)
+		mu.Lock()
+		settings = parent.settings
+		mu.Unlock()
+	}
 
-	l.providers[slotKey] = &slotEntry{
-		typeName:   typeName,
+	l := &ServiceLocator{
+		locatorState: &locatorState{
+			mu:        mu,
+			parent:    parent,
+			settings:  settings,
+			providers: map[any]*slotEntry{},
+			hooks:     map[any]*hookEntry{},
+			groups:    map[any][]*slotEntry{},
+		},
+	}
+
+	if parent != nil {
+		l.logger.Store(parent.logger.Load())
+	}
+
+	l.providers[LocatorSlot] = &slotEntry{
+		typeName:   getTypeName[*ServiceLocator](),
 		configured: true,
-		value:      value,
+		value:      l,
 	}
-	return value
+
+	return l
 }
 
-// ProvideFunc will inject an instance inside the given ServiceLocator and
-// "slotKey" that is created only when requested with a call to the [Use] or
-// [Invoke] functions.
-//
-// This is generic over "T" to check that instances returned by the "createFunc"
-// are compatible with "T" as it can also be an interface.
-func ProvideFunc[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error)) {
-	typeName := getTypeName[T]()
-	Logger.Printf(`[slot: %s] inject lazy provider`, typeName)
+// dependentView returns a view of this locator sharing the same state that
+// records every slot resolved through it as a dependency of "s".
+func (l *ServiceLocator) dependentView(s *slotEntry) *ServiceLocator {
+	return &ServiceLocator{
+		locatorState: l.locatorState,
+		dependent:    s,
+		ctx:          l.ctx,
+		configuring:  &configureFrame{s, l.configuring},
+	}
+}
 
-	l.providers[slotKey] = &slotEntry{
-		typeName:      typeName,
-		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
-		configured:    false,
+// resolutionView returns a view of this locator sharing the same state that
+// carries the context and the slots being configured by the resolution done
+// through "from".
+func (l *ServiceLocator) resolutionView(from *ServiceLocator) *ServiceLocator {
+	return &ServiceLocator{
+		locatorState: l.locatorState,
+		dependent:    l.dependent,
+		ctx:          from.ctx,
+		configuring:  from.configuring,
 	}
 }
 
-// useSlotValue tries to configure the slot for slotKey and if done correctly returns it.
-func useSlotValue[T any](l *ServiceLocator, slotKey slot[T]) (T, error) {
-	slot, ok := l.providers[slotKey]
-	if !ok {
-		return zero[T](), fmt.Errorf(`no injected value for type %s`, getTypeName[T]())
+// lookupProvider searches the entry for "slotKey" in this locator and then in
+// its parent scopes, it also returns the locator owning the entry. The lock of
+// the locator must be held.
+func (l *ServiceLocator) lookupProvider(slotKey any) (*slotEntry, *ServiceLocator, bool) {
+	for current := l; current != nil; current = current.parent {
+		if entry, ok := current.providers[slotKey]; ok {
+			return entry, current, true
+		}
 	}
 
-	if err := slot.ensureConfigured(l); err != nil {
-		return zero[T](), err
+	return nil, nil, false
+}
+
+// lookupHooks searches the entry for "hookKey" in this locator and then in
+// its parent scopes. If the entry found was created by [Subscribe] this also
+// returns the entries of the parent scopes it extends, the nearest first. The
+// lock of the locator must be held.
+func (l *ServiceLocator) lookupHooks(hookKey any) []*hookEntry {
+	entries := []*hookEntry{}
+	for current := l; current != nil; current = current.parent {
+		entry, ok := current.hooks[hookKey]
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, entry)
+		if !entry.inherit {
+			break
+		}
 	}
 
-	return slot.value.(T), nil
+	return entries
 }
 
-// Use retrieves the value of type T associated with the given slot key from
-// the provided [ServiceLocator] instance.
+// hookListeners returns the type name and a copy of the listeners of the
+// given hook, this takes the lock of the locator. The listeners are wrapped to
+// be timed if a callback was registered with [ServiceLocator.OnHookListener].
+// Listeners of parent scopes extended by [Subscribe] come first.
 //
-// If the [ServiceLocator] does not have a value for the slot key, or if the
-// value wasn't correctly configured (in the case of a lazy slot), an error
-// is returned.
-func Use[T any](l *ServiceLocator, slotKey slot[T]) (T, error) {
-	v, err := useSlotValue(l, slotKey)
-	if err != nil {
-		return zero[T](), err
+// This returns an error if the hook was provided for payloads of a type other
+// than "payloadType", as its listeners would not be able to handle them.
+func (l *ServiceLocator) hookListeners(hookKey any, payloadType reflect.Type) (string, []func(*ServiceLocator, any) error, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := l.lookupHooks(hookKey)
+	if len(entries) == 0 {
+		return "", nil, false, nil
 	}
 
-	return v, nil
-}
+	listeners := []func(*ServiceLocator, any) error{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := entries[i].checkPayload(payloadType); err != nil {
+			return "", nil, true, err
+		}
 
-// MustUse is the same as [Use] but panics if there is any error in locating the service
-func MustUse[T any](l *ServiceLocator, slotKey slot[T]) T {
-	v, err := useSlotValue(l, slotKey)
-	if err != nil {
-		panic(err)
+		entries[i].prune()
+		listeners = append(listeners, entries[i].listeners...)
 	}
 
-	return v
+	typeName := entries[0].typeName
+	if observe := l.settings.onHookListener; observe != nil {
+		for i, listener := range listeners {
+			listeners[i] = timedListener(typeName, i, listener, observe)
+		}
+	}
+
+	return typeName, listeners, true, nil
 }
 
-// Invoke is the same as [Use] but discards the value and just returns the error
-func Invoke[T any](l *ServiceLocator, slotKey slot[T]) error {
-	_, err := useSlotValue(l, slotKey)
-	if err != nil {
-		return err
+// setProvider registers a slot entry for the given slot key, this refuses to
+// replace [LocatorSlot] and returns false in that case.
+func (l *ServiceLocator) setProvider(slotKey any, entry *slotEntry) bool {
+	l.mu.Lock()
+	ok := l.putProvider(slotKey, entry)
+	l.mu.Unlock()
+
+	if ok {
+		l.emit(Event{Kind: EventProvide, TypeName: entry.typeName})
 	}
 
-	return nil
+	return ok
 }
 
-// MustInvoke is the same as [Invoke] but panics if there is any error in locating the service
-func MustInvoke[T any](l *ServiceLocator, slotKey slot[T]) {
-	if _, err := useSlotValue(l, slotKey); err != nil {
-		panic(err)
+// putProvider is the same as [ServiceLocator.setProvider] but doesn't emit
+// any event, the lock of the locator must be held.
+func (l *ServiceLocator) putProvider(slotKey any, entry *slotEntry) bool {
+	if slotKey == any(LocatorSlot) {
+		l.logf(`[slot: %s] cannot override the locator slot, ignored`, entry.typeName)
+		return false
 	}
-}
 
-//
+	if l.sealed {
+		l.logf(`[slot: %s] cannot provide in a sealed locator, ignored`, entry.typeName)
+		return false
+	}
+
+	if err := l.checkGoroutine(); err != nil {
+		l.logf(`[slot: %s] warning: %v`, entry.typeName, err)
+	}
+
+	if l.settings.captureSource && entry.source == "" {
+		entry.source = callerSource()
+	}
+
+	previous, existed := l.providers[slotKey]
+	if !existed {
+		l.slotKeys = append(l.slotKeys, slotKey)
+	}
+
+	if existed && previous.module != "" && entry.module != "" && previous.module != entry.module {
+		l.logf(`[slot: %s] warning: provided by both %q and %q`, entry.typeName, previous.module, entry.module)
+	}
+
+	if l.journal != nil {
+		*l.journal = append(*l.journal, providerChange{slotKey, previous, existed})
+	}
+
+	entry.slotKey = slotKey
+	l.providers[slotKey] = entry
+
+	// eager values with a cleanup are closed together with the lazy ones
+	if entry.configured && entry.configureFunc == nil && entry.cleanupFunc != nil {
+		l.configureOrder = append(l.configureOrder, entry)
+	}
+
+	return true
+}
+
+//
+// Slots
+//
+
+// Provide will inject a concrete instance inside the ServiceLocator "l" for
+// the given "slotKey". This should be used for injecting "static" services, for
+// instances whose construction depend on other services you should use the
+// [ProvideFunc] function.
+//
+// This is generic over "T" to check that instances returned by the "createFunc"
+// are compatible with "T" as it can also be an interface.
+func Provide[T any](l *ServiceLocator, slotKey slot[T], value T) T {
+	return provideValue(l, slotKey, value, "")
+}
+
+// provideValue implements [Provide] and [ProvideBy]
+func provideValue[T any](l *ServiceLocator, slotKey slot[T], value T, module string) T {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] provided value of type %T`, typeName, value)
+
+	entry, stored := valueEntry(l, typeName, value)
+	entry.module = module
+	l.setProvider(slotKey, entry)
+
+	return stored
+}
+
+// valueEntry builds the entry of an eagerly provided value like [Provide]
+// does: the value goes through the provide interceptors (see
+// [ServiceLocator.AddProvideInterceptor]) and gets the auto-closer if enabled.
+// This also returns the value as stored in the entry.
+func valueEntry[T any](l *ServiceLocator, typeName string, value T) (*slotEntry, T) {
+	stored := l.intercept(typeName, value)
+
+	var cleanupFunc func(any) error
+	if _, ok := stored.(io.Closer); ok {
+		cleanupFunc = l.autoCloseFunc()
+	}
+
+	entry := &slotEntry{
+		typeName:    typeName,
+		configured:  true,
+		value:       stored,
+		cleanupFunc: cleanupFunc,
+	}
+
+	if v, ok := stored.(T); ok {
+		return entry, v
+	}
+
+	return entry, value
+}
+
+// Override is like [Provide] but also returns the previous value of the slot
+// and true if the slot had a configured value, this is mostly useful in tests
+// to replace a service with a fake one.
+//
+// Slots that already used the previous value keep it, see [ResetCascade].
+func Override[T any](l *ServiceLocator, slotKey slot[T], value T) (T, bool) {
+	typeName := slotName(slotKey)
+
+	l.logf(`[slot: %s] override with value of type %T`, typeName, value)
+
+	l.mu.Lock()
+	previous, _, existed := l.lookupProvider(slotKey)
+	existed = existed && previous.configured && previous.extractFunc == nil
+
+	previousValue := zero[T]()
+	if existed {
+		previousValue, _ = assertSlotValue[T](previous.value)
+	}
+
+	ok := l.putProvider(slotKey, &slotEntry{
+		typeName:   typeName,
+		configured: true,
+		value:      value,
+	})
+	l.mu.Unlock()
+
+	if ok {
+		l.emit(Event{Kind: EventProvide, TypeName: typeName})
+	}
+
+	return previousValue, existed
+}
+
+// OverrideAs is like [Override] but takes a value of the concrete type "C"
+// and stores it in the interface slot, this avoids converting the value by
+// hand at each call site.
+//
+// Go doesn't allow constraining "C" to implement "I" when "I" is a type
+// parameter, so this panics if a "C" is not an "I".
+func OverrideAs[I, C any](l *ServiceLocator, slotKey slot[I], value C) (I, bool) {
+	v, ok := any(value).(I)
+	if !ok {
+		panic(fmt.Sprintf(`value of type %T is not a %s`, value, getTypeName[I]()))
+	}
+
+	return Override(l, slotKey, v)
+}
+
+// ProvideFunc will inject an instance inside the given ServiceLocator and
+// "slotKey" that is created only when requested with a call to the [Use] or
+// [Invoke] functions.
+//
+// This is generic over "T" to check that instances returned by the "createFunc"
+// are compatible with "T" as it can also be an interface.
+func ProvideFunc[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error)) {
+	provideFunc(l, slotKey, createFunc, "")
+}
+
+// provideFunc implements [ProvideFunc] and [ProvideFuncBy]
+func provideFunc[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error), module string) {
+	typeName := slotName(slotKey)
+	l.logf(`[slot: %s] inject lazy provider`, typeName)
+
+	l.setProvider(slotKey, &slotEntry{
+		typeName:      typeName,
+		configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+		configured:    false,
+		cleanupFunc:   l.autoCloseFunc(),
+		module:        module,
+	})
+}
+
+// ProvideFuncSimple is like [ProvideFunc] for constructors that don't depend
+// on other services, this makes the lack of dependencies explicit at the call
+// site. The value is created lazily and cached like for [ProvideFunc].
+func ProvideFuncSimple[T any](l *ServiceLocator, slotKey slot[T], createFunc func() (T, error)) {
+	ProvideFunc(l, slotKey, func(*ServiceLocator) (T, error) {
+		return createFunc()
+	})
+}
+
+// ProvideFuncOK is like [ProvideFunc] for constructors that can't fail, this
+// avoids wrapping them just to return a nil error. The value is created lazily
+// and cached like for [ProvideFunc].
+func ProvideFuncOK[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) T) {
+	ProvideFunc(l, slotKey, func(l *ServiceLocator) (T, error) {
+		return createFunc(l), nil
+	})
+}
+
+// ProvideFunc2Out is like [ProvideFunc] for a constructor creating the values
+// of two slots at once, for example a reader and a writer sharing the same
+// connection pool. The constructor is called the first time either slot is
+// used and both values are cached, so it runs once even if both slots get
+// resolved.
+//
+// The two values are always a matching pair: when either slot gets configured
+// again (for example after [Reset]) the constructor runs again and the other
+// slot is reset too, and using "bKey" returns an error if "aKey" got provided
+// separately (for example with [Override]).
+func ProvideFunc2Out[A, B any](l *ServiceLocator, aKey slot[A], bKey slot[B], createFunc func(*ServiceLocator) (A, B, error)) {
+	aName, bName := slotName(aKey), slotName(bKey)
+	l.logf(`[slot: %s] inject lazy provider together with %s`, aName, bName)
+
+	// "b" is the second value of the last pair, "fresh" tells if it wasn't
+	// used yet as the value of "bKey"
+	var mu sync.Mutex
+	var b B
+	var fresh bool
+
+	var bEntry *slotEntry
+	aEntry := &slotEntry{
+		typeName: aName,
+		configureFunc: func(l *ServiceLocator) (any, error) {
+			a, bValue, err := createFunc(l)
+			if err != nil {
+				return a, err
+			}
+
+			mu.Lock()
+			previous, unused := b, fresh
+			b, fresh = bValue, true
+			mu.Unlock()
+
+			// the previous value of "bKey" belongs to the previous pair
+			if unused {
+				if err := bEntry.cleanup(l, previous); err != nil {
+					l.logf(`warning: %v`, err)
+				}
+			}
+			l.invalidateLogged([]*slotEntry{bEntry})
+
+			return a, nil
+		},
+		cleanupFunc: l.autoCloseFunc(),
+	}
+	bEntry = &slotEntry{
+		typeName: bName,
+		configureFunc: func(l *ServiceLocator) (any, error) {
+			l.mu.Lock()
+			current, _, _ := l.lookupProvider(aKey)
+			l.mu.Unlock()
+
+			if current != aEntry {
+				return zero[B](), fmt.Errorf(`%s was provided separately, so it doesn't match %s`, aName, bName)
+			}
+
+			if _, err := Use(l, aKey); err != nil {
+				return zero[B](), err
+			}
+
+			mu.Lock()
+			used := !fresh
+			mu.Unlock()
+
+			// "bKey" got reset alone, so the pair must be created again
+			if used {
+				l.invalidateLogged([]*slotEntry{aEntry})
+
+				if _, err := Use(l, aKey); err != nil {
+					return zero[B](), err
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			fresh = false
+			return b, nil
+		},
+		cleanupFunc: l.autoCloseFunc(),
+	}
+
+	l.setProvider(aKey, aEntry)
+	l.setProvider(bKey, bEntry)
+}
+
+// ProvideFactory injects a factory of values of type "T" in "factoryKey".
+// The factory is created lazily by "build" like for [ProvideFunc], so its
+// dependencies are resolved only once, then callers can [Use] the factory and
+// call it any number of times to create fresh instances.
+//
+// The locator doesn't keep track of the instances created by the factory, so
+// they are not closed by [ServiceLocator.Close] and callers own them.
+func ProvideFactory[T any](l *ServiceLocator, factoryKey slot[func() (T, error)], build func(*ServiceLocator) (func() (T, error), error)) {
+	ProvideFunc(l, factoryKey, build)
+}
+
+// ProvideFuncValidated is like [ProvideFunc] but after the instance is created
+// it gets checked with "validate". If the validation fails its error is
+// returned by [Use] and the instance is not cached.
+func ProvideFuncValidated[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error), validate func(T) error) {
+	ProvideFunc(l, slotKey, func(l *ServiceLocator) (T, error) {
+		v, err := createFunc(l)
+		if err != nil {
+			return zero[T](), err
+		}
+
+		if err := validate(v); err != nil {
+			return zero[T](), err
+		}
+
+		return v, nil
+	})
+}
+
+// ProvideStruct is like [ProvideFunc] but splits the resolution of the
+// dependencies from the construction of the value: "binder" resolves the
+// dependencies into a "Deps" struct and "build" creates the value from it.
+// Then "build" can be tested by calling it with a hand-built "Deps", for
+// example
+//
+//	type ServerDeps struct {
+//		Config *Config
+//		DB     *sql.DB
+//	}
+//
+//	sl.ProvideStruct(l, ServerSlot, func(l *sl.ServiceLocator) (ServerDeps, error) {
+//		config, db, err := sl.Use2(l, ConfigSlot, DatabaseSlot)
+//		return ServerDeps{config, db}, err
+//	}, NewServer)
+func ProvideStruct[Deps, T any](l *ServiceLocator, slotKey slot[T], binder func(*ServiceLocator) (Deps, error), build func(Deps) (T, error)) {
+	ProvideFunc(l, slotKey, func(l *ServiceLocator) (T, error) {
+		deps, err := binder(l)
+		if err != nil {
+			return zero[T](), err
+		}
+
+		return build(deps)
+	})
+}
+
+// ProvideFuncIf is like [ProvideFunc] but when the slot is used the boolean
+// "flagKey" slot is resolved first and if false the value is not created and
+// [Use] returns an error wrapping [ErrSlotDisabled].
+//
+// The flag is evaluated every time the slot is used until the value gets
+// created, after that the value is cached like for [ProvideFunc] (unless the
+// slot gets [Reset]).
+func ProvideFuncIf[T any](l *ServiceLocator, slotKey slot[T], flagKey slot[bool], createFunc func(*ServiceLocator) (T, error)) {
+	ProvideFunc(l, slotKey, func(l *ServiceLocator) (T, error) {
+		enabled, err := Use(l, flagKey)
+		if err != nil {
+			return zero[T](), err
+		}
+		if !enabled {
+			return zero[T](), fmt.Errorf(`%w: type %s`, ErrSlotDisabled, getTypeName[T]())
+		}
+
+		return createFunc(l)
+	})
+}
+
+// useSlotValue tries to configure the slot for slotKey and if done correctly returns it.
+func useSlotValue[T any](l *ServiceLocator, slotKey slot[T]) (T, error) {
+	slot, v, ok, err := l.useEntry(slotKey, slotName(slotKey))
+	if err != nil {
+		return zero[T](), err
+	}
+
+	if !ok {
+		l.mu.Lock()
+		stubMissing := l.stubMissing
+		l.mu.Unlock()
+
+		if stubMissing {
+			l.logf(`[slot: %s] stubbed with zero value`, slotName(slotKey))
+			return zero[T](), nil
+		}
+
+		return zero[T](), notFoundError(slotKey)
+	}
+
+	if slot.extractFunc != nil {
+		return extractSlotValue[T](l, slot)
+	}
+
+	l.mu.Lock()
+	assertNonNil := l.settings.assertNonNilOnUse
+	l.mu.Unlock()
+
+	if assertNonNil && isNil(v) {
+		return zero[T](), fmt.Errorf(`slot of type %s resolved to a nil value`, slot.typeName)
+	}
+
+	return assertSlotValue[T](v)
+}
+
+// checkUse returns an error if the slot with the given key can't be used from
+// this locator, because the locator is bound to another goroutine or the slot
+// is behind a barrier. The lock of the locator must be held.
+func (l *ServiceLocator) checkUse(slotKey any, typeName string) error {
+	if err := l.checkGoroutine(); err != nil {
+		return fmt.Errorf(`using %s: %w`, typeName, err)
+	}
+
+	return l.checkBarrier(slotKey, typeName)
+}
+
+// useEntry implements [Use] for slots of any type: it finds the slot with the
+// given key, checks it can be used (see [ServiceLocator.checkUse]), records
+// it as used and configures it if needed. This returns false if the slot is
+// missing, slots derived from the context are returned without a value.
+func (l *ServiceLocator) useEntry(slotKey any, typeName string) (*slotEntry, any, bool, error) {
+	l.mu.Lock()
+	slot, owner, ok := l.lookupProvider(slotKey)
+	if !ok {
+		err := l.checkGoroutine()
+		l.mu.Unlock()
+
+		if err != nil {
+			return nil, nil, false, fmt.Errorf(`using %s: %w`, typeName, err)
+		}
+
+		return nil, nil, false, nil
+	}
+
+	if err := l.checkUse(slotKey, typeName); err != nil {
+		l.mu.Unlock()
+		return nil, nil, true, err
+	}
+
+	scopedCopy := slot.scoped && owner.locatorState != l.locatorState
+	if scopedCopy {
+		slot = slot.scopedCopy()
+		l.putProvider(slotKey, slot)
+		owner = l
+	}
+
+	if !slot.configured {
+		if err := l.configuring.cycleError(slot); err != nil {
+			l.mu.Unlock()
+			return nil, nil, true, err
+		}
+	}
+
+	if l.dependent != nil {
+		l.dependent.addDependency(slot)
+	} else {
+		l.recordUse(slot)
+	}
+	l.mu.Unlock()
+
+	if scopedCopy {
+		l.emit(Event{Kind: EventProvide, TypeName: slot.typeName})
+	}
+
+	if slot.extractFunc != nil {
+		return slot, nil, true, nil
+	}
+
+	if l.ctx != nil || l.configuring != nil {
+		owner = owner.resolutionView(l)
+	}
+
+	v, err := slot.ensureConfigured(owner)
+	if err != nil {
+		return nil, nil, true, err
+	}
+
+	return slot, v, true, nil
+}
+
+// assertSlotValue converts a value returned by a provider to the type of its
+// slot, this can only fail for untyped providers like the ones registered with
+// [ProvideFuncAll].
+func assertSlotValue[T any](v any) (T, error) {
+	// a nil interface value is stored as a nil "any"
+	if v == nil {
+		return zero[T](), nil
+	}
+
+	t, ok := v.(T)
+	if !ok {
+		return zero[T](), fmt.Errorf(`slot expects %s but provider returned %T`, getTypeName[T](), v)
+	}
+
+	return t, nil
+}
+
+// Use retrieves the value of type T associated with the given slot key from
+// the provided [ServiceLocator] instance.
+//
+// If the [ServiceLocator] does not have a value for the slot key, or if the
+// value wasn't correctly configured (in the case of a lazy slot), an error
+// is returned.
+func Use[T any](l *ServiceLocator, slotKey slot[T]) (T, error) {
+	v, err := useSlotValue(l, slotKey)
+	if err != nil {
+		return zero[T](), err
+	}
+
+	return v, nil
+}
+
+// TryUse is the same as [Use] but reports with a boolean if the slot resolved
+// successfully instead of returning an error. A slot holding the zero value of
+// "T" (like 0 or false) resolves to that value and true, so the boolean is the
+// only way to tell a missing slot apart.
+func TryUse[T any](l *ServiceLocator, slotKey slot[T]) (T, bool) {
+	v, err := useSlotValue(l, slotKey)
+	if err != nil {
+		return zero[T](), false
+	}
+
+	return v, true
+}
+
+// UseOr is the same as [TryUse] but returns "fallback" if the slot doesn't
+// resolve, a slot holding the zero value of "T" still resolves to it.
+func UseOr[T any](l *ServiceLocator, slotKey slot[T], fallback T) T {
+	if v, ok := TryUse(l, slotKey); ok {
+		return v
+	}
+
+	return fallback
+}
+
+// UseOrProvide resolves the slot like [Use] but if the slot has no provider
+// (in this locator and its parent scopes) "createFunc" is registered as its
+// lazy provider first, like for [ProvideFunc]. This is useful for optional
+// dependencies with a default built inline. The check and the registration are
+// atomic, so concurrent callers register only one provider.
+func UseOrProvide[T any](l *ServiceLocator, slotKey slot[T], createFunc func(*ServiceLocator) (T, error)) (T, error) {
+	typeName := slotName(slotKey)
+
+	registered := false
+	cleanupFunc := l.autoCloseFunc()
+
+	l.mu.Lock()
+	if _, _, ok := l.lookupProvider(slotKey); !ok {
+		l.logf(`[slot: %s] inject lazy provider on use`, typeName)
+
+		registered = l.putProvider(slotKey, &slotEntry{
+			typeName:      typeName,
+			configureFunc: func(l *ServiceLocator) (any, error) { return createFunc(l) },
+			cleanupFunc:   cleanupFunc,
+		})
+	}
+	l.mu.Unlock()
+
+	if registered {
+		l.emit(Event{Kind: EventProvide, TypeName: typeName})
+	}
+
+	return useSlotValue(l, slotKey)
+}
+
+// OnResolve registers a callback called once with the value of the given slot
+// right after it gets configured, for example to register the metrics
+// collector of a service only if the service is used. If the slot is already
+// configured the callback is called immediately and its error is returned.
+//
+// Otherwise the errors of the callbacks are returned by the [Use] (or
+// similar) call configuring the slot, the slot stays configured so the next
+// calls succeed. The callbacks are tied to the slot currently provided for
+// "slotKey" (in this locator or in its parent scopes) and are dropped if the
+// slot gets provided again.
+func OnResolve[T any](l *ServiceLocator, slotKey slot[T], fn func(*ServiceLocator, T) error) error {
+	l.mu.Lock()
+	s, _, ok := l.lookupProvider(slotKey)
+	if !ok {
+		l.mu.Unlock()
+		return notFoundError(slotKey)
+	}
+
+	if !s.configured {
+		s.onResolve = append(s.onResolve, func(l *ServiceLocator, v any) error {
+			t, err := assertSlotValue[T](v)
+			if err != nil {
+				return err
+			}
+
+			return fn(l, t)
+		})
+		l.mu.Unlock()
+		return nil
+	}
+
+	v := s.value
+	l.mu.Unlock()
+
+	t, err := assertSlotValue[T](v)
+	if err != nil {
+		return err
+	}
+
+	return fn(l, t)
+}
+
+// UseVerbose is the same as [Use] but on failure the error also tells if the
+// slot is registered (so it failed to be configured) and lists the type names
+// of all the slots registered in this locator and in its parent scopes. This
+// helps spotting missing registrations, the extra work is only done when the
+// resolution fails.
+func UseVerbose[T any](l *ServiceLocator, slotKey slot[T]) (T, error) {
+	v, err := useSlotValue(l, slotKey)
+	if err == nil {
+		return v, nil
+	}
+
+	l.mu.Lock()
+	_, _, registered := l.lookupProvider(slotKey)
+
+	seen := map[any]bool{}
+	typeNames := []string{}
+	for current := l; current != nil; current = current.parent {
+		for _, key := range current.slotKeys {
+			if !seen[key] {
+				seen[key] = true
+				typeNames = append(typeNames, current.providers[key].typeName)
+			}
+		}
+	}
+	l.mu.Unlock()
+
+	status := `not registered`
+	if registered {
+		status = `registered but failed to resolve`
+	}
+
+	return zero[T](), fmt.Errorf(`%w (slot %s, registered slots: [%s])`, err, status, strings.Join(typeNames, ", "))
+}
+
+// Use2 is the same as [Use] but resolves two slots at once, the first error
+// encountered is returned.
+func Use2[A, B any](l *ServiceLocator, a slot[A], b slot[B]) (A, B, error) {
+	va, err := useSlotValue(l, a)
+	if err != nil {
+		return zero[A](), zero[B](), err
+	}
+
+	vb, err := useSlotValue(l, b)
+	if err != nil {
+		return zero[A](), zero[B](), err
+	}
+
+	return va, vb, nil
+}
+
+// Use3 is the same as [Use] but resolves three slots at once, the first error
+// encountered is returned.
+func Use3[A, B, C any](l *ServiceLocator, a slot[A], b slot[B], c slot[C]) (A, B, C, error) {
+	va, vb, err := Use2(l, a, b)
+	if err != nil {
+		return zero[A](), zero[B](), zero[C](), err
+	}
+
+	vc, err := useSlotValue(l, c)
+	if err != nil {
+		return zero[A](), zero[B](), zero[C](), err
+	}
+
+	return va, vb, vc, nil
+}
+
+// UseConcrete resolves an interface slot like [Use] and then asserts the
+// value to the concrete type "C", returning an error if the stored value is
+// not of that type.
+func UseConcrete[I, C any](l *ServiceLocator, slotKey slot[I]) (C, error) {
+	v, err := useSlotValue(l, slotKey)
+	if err != nil {
+		return zero[C](), err
+	}
+
+	c, ok := any(v).(C)
+	if !ok {
+		return zero[C](), fmt.Errorf(`slot of type %s holds a value of type %T, expected %s`, getTypeName[I](), v, getTypeName[C]())
+	}
+
+	return c, nil
+}
+
+// UseAs resolves a slot like [Use] and converts its value to "Dst", usually
+// an interface implemented by "Src". This avoids declaring a slot for each
+// interface view of a concrete service, for example
+//
+//	greeter, err := sl.UseAs[Greeter](l, EnglishGreeterSlot)
+//
+// Go doesn't allow constraining "Src" to be assignable to "Dst", so this
+// returns an error if it isn't. A nil interface value converts to a nil "Dst".
+func UseAs[Dst, Src any](l *ServiceLocator, srcKey slot[Src]) (Dst, error) {
+	srcType := reflect.TypeOf((*Src)(nil)).Elem()
+	dstType := reflect.TypeOf((*Dst)(nil)).Elem()
+	if !srcType.AssignableTo(dstType) {
+		return zero[Dst](), fmt.Errorf(`slot of type %s is not assignable to %s`, slotName(srcKey), getTypeName[Dst]())
+	}
+
+	v, err := useSlotValue(l, srcKey)
+	if err != nil {
+		return zero[Dst](), err
+	}
+
+	dst, _ := any(v).(Dst)
+	return dst, nil
+}
+
+// Rebind resolves "oldKey" like [UseAs] and provides the value under "newKey"
+// with [Provide], so that the same instance is reachable from both slots. This
+// helps incremental refactors where consumers move from one slot to another.
+// This returns an error if "oldKey" can't be resolved or if "Old" is not
+// assignable to "New".
+func Rebind[Old, New any](l *ServiceLocator, oldKey slot[Old], newKey slot[New]) error {
+	v, err := UseAs[New](l, oldKey)
+	if err != nil {
+		return fmt.Errorf(`rebinding %s to %s: %w`, slotName(oldKey), slotName(newKey), err)
+	}
+
+	Provide(l, newKey, v)
+	return nil
+}
+
+// UseImplementing returns the values of all the configured slots of this
+// locator (and of its parent scopes) implementing the interface "I", in
+// registration order starting from this locator. This enables discovering
+// services without declaring a slot for each group of them.
+//
+// Lazy slots that are not configured yet are skipped, this doesn't configure
+// any slot.
+func UseImplementing[I any](l *ServiceLocator) []I {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seen := map[any]bool{}
+	values := []I{}
+	for current := l; current != nil; current = current.parent {
+		for _, key := range current.slotKeys {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			s := current.providers[key]
+			if !s.configured {
+				continue
+			}
+
+			if v, ok := s.value.(I); ok {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
+// MustUse is the same as [Use] but panics with a [*SlotError] if there is any
+// error in locating the service
+func MustUse[T any](l *ServiceLocator, slotKey slot[T]) T {
+	v, err := useSlotValue(l, slotKey)
+	if err != nil {
+		panic(&SlotError{TypeName: slotName(slotKey), Err: err})
+	}
+
+	return v
+}
+
+// MustUse2 is the same as [Use2] but panics with a [*SlotError] naming the
+// first slot that failed to resolve
+func MustUse2[A, B any](l *ServiceLocator, a slot[A], b slot[B]) (A, B) {
+	return MustUse(l, a), MustUse(l, b)
+}
+
+// MustUse3 is the same as [Use3] but panics with a [*SlotError] naming the
+// first slot that failed to resolve
+func MustUse3[A, B, C any](l *ServiceLocator, a slot[A], b slot[B], c slot[C]) (A, B, C) {
+	return MustUse(l, a), MustUse(l, b), MustUse(l, c)
+}
+
+// Invoke is the same as [Use] but discards the value and just returns the error
+func Invoke[T any](l *ServiceLocator, slotKey slot[T]) error {
+	_, err := useSlotValue(l, slotKey)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Getter returns a function that resolves the given slot with [Use] every time
+// it is called, this is useful to pass lazy access to a single service to code
+// that doesn't know about the [ServiceLocator].
+func Getter[T any](l *ServiceLocator, slotKey slot[T]) func() (T, error) {
+	return func() (T, error) {
+		return Use(l, slotKey)
+	}
+}
+
+// MustGetter is the same as [Getter] but the returned function panics if
+// there is any error in locating the service
+func MustGetter[T any](l *ServiceLocator, slotKey slot[T]) func() T {
+	return func() T {
+		return MustUse(l, slotKey)
+	}
+}
+
+// MustInvoke is the same as [Invoke] but panics with a [*SlotError] if there
+// is any error in locating the service
+func MustInvoke[T any](l *ServiceLocator, slotKey slot[T]) {
+	if _, err := useSlotValue(l, slotKey); err != nil {
+		panic(&SlotError{TypeName: slotName(slotKey), Err: err})
+	}
+}
+
+// Recover calls "fn" and converts panics raised by the Must functions (like
+// [MustUse], [MustInvoke] and [MustUseHook]) to an error, this lets handlers
+// use the Must functions without crashing the whole application when a
+// service is missing. Panics with values other than [*SlotError] are
+// propagated.
+func (l *ServiceLocator) Recover(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slotErr, ok := r.(*SlotError)
+			if !ok {
+				panic(r)
+			}
+
+			err = slotErr
+		}
+	}()
+
+	return fn()
+}
+
+//
 // Hooks
 //
 
@@ -248,27 +1695,56 @@ func MustInvoke[T any](l *ServiceLocator, slotKey slot[T]) {
 //
 // For example to easily enable or disable routes in an http server based on
 // some environment variables when setting up the application.
+//
+// Nil listeners are skipped (and logged), so the indices of the next listeners
+// are shifted.
+//
+// If "T" is an interface type each listener receives the dispatched value as
+// "T" whatever its dynamic type (or nil), so listeners interested in a
+// specific implementation must type assert it themselves.
 func ProvideHook[T any](l *ServiceLocator, hookKey hook[T], listeners ...Hook[T]) {
 	typeName := getTypeName[T]()
-	Logger.Printf(`[hook: %s] injecting hooks`, typeName)
+	l.logf(`[hook: %s] injecting hooks`, typeName)
 
 	// cast type safe listeners to internal untyped version to put inside the hook map
-	anyListeners := make([]func(*ServiceLocator, any) error, len(listeners))
-	for i, l := range listeners {
-		ll := l
-		anyListeners[i] = func(l *ServiceLocator, a any) error {
-			t, ok := a.(T)
-			if !ok {
-				panic(`illegal state`)
-			}
-
-			return ll(l, t)
+	anyListeners := make([]func(*ServiceLocator, any) error, 0, len(listeners))
+	for i, listener := range listeners {
+		if listener == nil {
+			l.logf(`[hook: %s] skipped nil listener at index %d`, typeName, i)
+			continue
 		}
+
+		anyListeners = append(anyListeners, toAnyListener(listener))
 	}
 
+	l.mu.Lock()
 	l.hooks[hookKey] = &hookEntry{
-		typeName:  typeName,
-		listeners: anyListeners,
+		typeName:    typeName,
+		payloadType: payloadTypeOf[T](),
+		listeners:   anyListeners,
+	}
+	l.mu.Unlock()
+}
+
+// payloadTypeOf returns the type of the payloads of hooks of type "T"
+func payloadTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// toAnyListener casts a type safe listener to the internal untyped version
+func toAnyListener[T any](listener Hook[T]) func(*ServiceLocator, any) error {
+	return func(l *ServiceLocator, a any) error {
+		// a nil interface payload is boxed as a nil "any"
+		if a == nil {
+			return listener(l, zero[T]())
+		}
+
+		t, ok := a.(T)
+		if !ok {
+			panic(`illegal state`)
+		}
+
+		return listener(l, t)
 	}
 }
 
@@ -277,15 +1753,38 @@ func ProvideHook[T any](l *ServiceLocator, hookKey hook[T], listeners ...Hook[T]
 //
 // For example to attach some routes to a given router in a deterministic order
 // a composable manner.
+//
+// Listeners are called in order and the dispatch stops at the first error. A
+// listener that fully handles the value can return [ErrStopPropagation] to
+// skip the next listeners, in this case UseHook returns nil.
+//
+// The dispatch uses the listeners registered when it starts, listeners added
+// or replaced meanwhile (even by the listeners themselves) are used starting
+// from the next dispatch.
 func UseHook[T any](l *ServiceLocator, hookKey hook[T], value T) error {
-	hookEntry, ok := l.hooks[hookKey]
+	typeName, listeners, ok, err := l.hookListeners(hookKey, payloadTypeOf[T]())
+	if err != nil {
+		return err
+	}
 	if !ok {
-		return fmt.Errorf(`no injected hooks for hook of type %s`, hookEntry.typeName)
+		return fmt.Errorf(`no injected hooks for hook of type %s`, getTypeName[T]())
 	}
 
-	Logger.Printf(`[hook: %s] calling hook with value of type %T`, hookEntry.typeName, value)
-	for _, hookFunc := range hookEntry.listeners {
+	return dispatchHook(l, typeName, listeners, value)
+}
+
+// dispatchHook calls the listeners of a hook in order like [UseHook], the
+// listeners are a snapshot taken by [ServiceLocator.hookListeners] so
+// listeners added or removed meanwhile don't change this dispatch.
+func dispatchHook[T any](l *ServiceLocator, typeName string, listeners []func(*ServiceLocator, any) error, value T) error {
+	l.logf(`[hook: %s] calling hook with value of type %T`, typeName, value)
+	l.emit(Event{Kind: EventHookDispatch, TypeName: typeName})
+	for _, hookFunc := range listeners {
 		if err := hookFunc(l, value); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				return nil
+			}
+
 			return err
 		}
 	}
@@ -293,13 +1792,29 @@ func UseHook[T any](l *ServiceLocator, hookKey hook[T], value T) error {
 	return nil
 }
 
-// MustUseHook is the same as [UseHook] but panics if there is some error
+// MustUseHook is the same as [UseHook] but panics with a [*SlotError] if
+// there is some error
 func MustUseHook[T any](l *ServiceLocator, hookKey hook[T], value T) {
 	if err := UseHook(l, hookKey, value); err != nil {
-		panic(err)
+		panic(&SlotError{TypeName: getTypeName[T](), Err: err})
 	}
 }
 
+// UseHookOptional is the same as [UseHook] but a hook with no injected
+// listeners is a valid no-op instead of an error. This is useful for optional
+// extension points that do nothing when unused.
+func UseHookOptional[T any](l *ServiceLocator, hookKey hook[T], value T) error {
+	typeName, listeners, ok, err := l.hookListeners(hookKey, payloadTypeOf[T]())
+	if err != nil {
+		return err
+	}
+	if !ok || len(listeners) == 0 {
+		return nil
+	}
+
+	return dispatchHook(l, typeName, listeners, value)
+}
+
 // getTypeName is a trick to get the name of a type (even if it is an
 // interface type)
 func getTypeName[T any]() string {
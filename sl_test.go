@@ -1,10 +1,16 @@
 package sl_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aziis98/go-sl"
 	"gotest.tools/assert"
@@ -71,3 +77,853 @@ func TestBasic(t *testing.T) {
 		Bar: "foo baz",
 	})
 }
+
+func TestSetLogger(t *testing.T) {
+	previous := sl.Logger
+
+	var buf bytes.Buffer
+	restore := sl.SetLogger(log.New(&buf, "", 0))
+
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	restore()
+
+	assert.Assert(t, buf.Len() > 0)
+	assert.Equal(t, sl.Logger, previous)
+}
+
+func TestLocatorSlot(t *testing.T) {
+	l := sl.New()
+
+	assert.Equal(t, sl.MustUse(l, sl.LocatorSlot), l)
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		inner, err := sl.Use(l, sl.LocatorSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Config{Foo: fmt.Sprintf("%T", inner)}, nil
+	})
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "*sl.ServiceLocator")
+
+	sl.Provide(l, sl.LocatorSlot, sl.New())
+	assert.Equal(t, sl.MustUse(l, sl.LocatorSlot), l)
+}
+
+func TestErrorChain(t *testing.T) {
+	l := sl.New()
+
+	errLeaf := errors.New("leaf error")
+
+	sl.ProvideFunc(l, LoggerSlot, func(l *sl.ServiceLocator) (*log.Logger, error) {
+		return nil, errLeaf
+	})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		logger, err := sl.Use(l, LoggerSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExampleService{Logger: logger}, nil
+	})
+
+	_, err := sl.Use(l, ExampleServiceSlot)
+	assert.Error(t, err, "configuring *sl_test.ExampleService: configuring *log.Logger: leaf error")
+	assert.Assert(t, errors.Is(err, errLeaf))
+}
+
+func TestProvideFuncValidated(t *testing.T) {
+	l := sl.New()
+
+	errEmptyFoo := errors.New("empty foo")
+
+	foo := ""
+	sl.ProvideFuncValidated(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: foo}, nil
+	}, func(c *Config) error {
+		if c.Foo == "" {
+			return errEmptyFoo
+		}
+
+		return nil
+	})
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, errEmptyFoo))
+
+	foo = "foo"
+	config, err := sl.Use(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+}
+
+func TestUse2Use3(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.Provide(l, LoggerSlot, log.Default())
+
+	config, logger, err := sl.Use2(l, ConfigSlot, LoggerSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+	assert.Equal(t, logger, log.Default())
+
+	_, _, err = sl.Use2(l, ExampleServiceSlot, LoggerSlot)
+	assert.ErrorContains(t, err, "*sl_test.ExampleService")
+
+	_, _, err = sl.Use2(l, ConfigSlot, ExampleServiceSlot)
+	assert.ErrorContains(t, err, "*sl_test.ExampleService")
+
+	_, _, _, err = sl.Use3(l, ConfigSlot, LoggerSlot, ExampleServiceSlot)
+	assert.ErrorContains(t, err, "*sl_test.ExampleService")
+
+	sl.Provide(l, ExampleServiceSlot, &ExampleService{Bar: "bar"})
+
+	_, _, example, err := sl.Use3(l, ConfigSlot, LoggerSlot, ExampleServiceSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, example.Bar, "bar")
+}
+
+func TestProvideFuncIf(t *testing.T) {
+	l := sl.New()
+
+	featureSlot := sl.NewSlot[bool]()
+	sl.Provide(l, featureSlot, false)
+
+	sl.ProvideFuncIf(l, ConfigSlot, featureSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotDisabled))
+
+	sl.Provide(l, featureSlot, true)
+
+	config, err := sl.Use(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+
+	_, err = sl.Use(l, ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+type Greeter interface {
+	Greet() string
+}
+
+type EnglishGreeter struct{ Name string }
+
+func (g *EnglishGreeter) Greet() string { return "Hello " + g.Name }
+
+type ItalianGreeter struct{ Name string }
+
+func (g *ItalianGreeter) Greet() string { return "Ciao " + g.Name }
+
+var GreeterSlot = sl.NewSlot[Greeter]()
+
+func TestUseConcrete(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide[Greeter](l, GreeterSlot, &EnglishGreeter{Name: "World"})
+
+	greeter, err := sl.UseConcrete[Greeter, *EnglishGreeter](l, GreeterSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, greeter.Name, "World")
+
+	_, err = sl.UseConcrete[Greeter, *ItalianGreeter](l, GreeterSlot)
+	assert.Error(t, err, "slot of type sl_test.Greeter holds a value of type *sl_test.EnglishGreeter, expected *sl_test.ItalianGreeter")
+}
+
+// NamedGreeter embeds [Greeter]
+type NamedGreeter interface {
+	Greeter
+	GreeterName() string
+}
+
+type FrenchGreeter struct{ Name string }
+
+func (g *FrenchGreeter) Greet() string       { return "Bonjour " + g.Name }
+func (g *FrenchGreeter) GreeterName() string { return g.Name }
+
+func TestUseAs(t *testing.T) {
+	l := sl.New()
+
+	englishSlot := sl.NewSlot[*EnglishGreeter]()
+	sl.Provide(l, englishSlot, &EnglishGreeter{Name: "World"})
+
+	greeter, err := sl.UseAs[Greeter](l, englishSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, greeter.Greet(), "Hello World")
+
+	_, err = sl.UseAs[NamedGreeter](l, englishSlot)
+	assert.Error(t, err, "slot of type *sl_test.EnglishGreeter is not assignable to sl_test.NamedGreeter")
+
+	namedSlot := sl.NewSlot[NamedGreeter]()
+	sl.Provide[NamedGreeter](l, namedSlot, &FrenchGreeter{Name: "Monde"})
+
+	greeter, err = sl.UseAs[Greeter](l, namedSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, greeter.Greet(), "Bonjour Monde")
+
+	_, err = sl.UseAs[Greeter](l, sl.NewSlot[*FrenchGreeter]())
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+func TestUseHookOptional(t *testing.T) {
+	l := sl.New()
+
+	exampleHook := sl.NewHook[string]()
+
+	assert.ErrorContains(t, sl.UseHook(l, exampleHook, "foo"), "no injected hooks")
+	assert.NilError(t, sl.UseHookOptional(l, exampleHook, "foo"))
+
+	sl.ProvideHook(l, exampleHook)
+	assert.NilError(t, sl.UseHookOptional(l, exampleHook, "foo"))
+
+	called := []string{}
+	sl.ProvideHook(l, exampleHook, func(l *sl.ServiceLocator, s string) error {
+		called = append(called, s)
+		return nil
+	})
+
+	assert.NilError(t, sl.UseHook(l, exampleHook, "foo"))
+	assert.NilError(t, sl.UseHookOptional(l, exampleHook, "bar"))
+	assert.DeepEqual(t, called, []string{"foo", "bar"})
+}
+
+func TestOverride(t *testing.T) {
+	l := sl.New()
+
+	previous, existed := sl.Override(l, ConfigSlot, &Config{Foo: "foo"})
+	assert.Assert(t, previous == nil)
+	assert.Equal(t, existed, false)
+
+	config := sl.MustUse(l, ConfigSlot)
+
+	previous, existed = sl.Override(l, ConfigSlot, &Config{Foo: "bar"})
+	assert.Equal(t, previous, config)
+	assert.Equal(t, existed, true)
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "bar")
+}
+
+func TestOverrideNilInterface(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, GreeterSlot, nil)
+
+	previous, existed := sl.Override[Greeter](l, GreeterSlot, &EnglishGreeter{Name: "World"})
+	assert.Assert(t, previous == nil)
+	assert.Equal(t, existed, true)
+	assert.Equal(t, sl.MustUse(l, GreeterSlot).Greet(), "Hello World")
+}
+
+func TestGetter(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+
+	getConfig := sl.Getter(l, ConfigSlot)
+	mustGetConfig := sl.MustGetter(l, ConfigSlot)
+
+	config, err := getConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+	assert.Equal(t, mustGetConfig(), config)
+
+	sl.Override(l, ConfigSlot, &Config{Foo: "bar"})
+
+	config, err = getConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "bar")
+	assert.Equal(t, mustGetConfig(), config)
+
+	_, err = sl.Getter(l, ExampleServiceSlot)()
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+func TestGetterCycle(t *testing.T) {
+	l := sl.New()
+
+	getConfig := sl.Getter(l, ConfigSlot)
+	sl.ProvideFunc(l, ConfigSlot, func(*sl.ServiceLocator) (*Config, error) {
+		return getConfig()
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := sl.Use(l, ConfigSlot)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.Assert(t, errors.Is(err, sl.ErrDependencyCycle))
+	case <-time.After(time.Second):
+		t.Fatal("deadlock using a slot while configuring itself")
+	}
+}
+
+func TestOverrideAs(t *testing.T) {
+	l := sl.New()
+
+	english := &EnglishGreeter{Name: "World"}
+	sl.Provide[Greeter](l, GreeterSlot, english)
+
+	previous, existed := sl.OverrideAs(l, GreeterSlot, &ItalianGreeter{Name: "Mondo"})
+	assert.Equal(t, previous, Greeter(english))
+	assert.Equal(t, existed, true)
+	assert.Equal(t, sl.MustUse(l, GreeterSlot).Greet(), "Ciao Mondo")
+
+	defer func() {
+		assert.Equal(t, recover(), "value of type *sl_test.Config is not a sl_test.Greeter")
+	}()
+	sl.OverrideAs(l, GreeterSlot, &Config{})
+}
+
+func TestRecover(t *testing.T) {
+	l := sl.New()
+
+	err := l.Recover(func() error {
+		sl.MustUse(l, ConfigSlot)
+		return nil
+	})
+
+	var slotErr *sl.SlotError
+	assert.Assert(t, errors.As(err, &slotErr))
+	assert.Equal(t, slotErr.TypeName, "*sl_test.Config")
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	errReturned := errors.New("returned")
+	err = l.Recover(func() error {
+		return errReturned
+	})
+	assert.Equal(t, err, errReturned)
+
+	defer func() {
+		assert.Equal(t, recover(), "unrelated")
+	}()
+	l.Recover(func() error {
+		panic("unrelated")
+	})
+}
+
+func TestNewSlotWithDefault(t *testing.T) {
+	greetingSlot, provideDefaultGreeting := sl.NewSlotWithDefault("Hello")
+	otherGreetingSlot, _ := sl.NewSlotWithDefault("Hello")
+	assert.Assert(t, greetingSlot != otherGreetingSlot)
+
+	l := sl.New()
+	assert.ErrorContains(t, sl.Invoke(l, greetingSlot), "no injected value")
+
+	provideDefaultGreeting(l)
+	assert.Equal(t, sl.MustUse(l, greetingSlot), "Hello")
+	assert.ErrorContains(t, sl.Invoke(l, otherGreetingSlot), "no injected value")
+
+	sl.Provide(l, greetingSlot, "Ciao")
+	assert.Equal(t, sl.MustUse(l, greetingSlot), "Ciao")
+}
+
+func TestUseImplementing(t *testing.T) {
+	englishSlot := sl.NewSlot[*EnglishGreeter]()
+	italianSlot := sl.NewSlot[*ItalianGreeter]()
+	lazyItalianSlot := sl.NewSlot[*ItalianGreeter]()
+
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{})
+	english := sl.Provide(l, englishSlot, &EnglishGreeter{Name: "World"})
+	sl.ProvideFunc(l, lazyItalianSlot, func(l *sl.ServiceLocator) (*ItalianGreeter, error) {
+		return &ItalianGreeter{Name: "Lazy"}, nil
+	})
+
+	scope := l.Scope()
+	italian := sl.Provide(scope, italianSlot, &ItalianGreeter{Name: "Mondo"})
+
+	assert.DeepEqual(t, sl.UseImplementing[Greeter](l), []Greeter{english})
+	assert.DeepEqual(t, sl.UseImplementing[Greeter](scope), []Greeter{italian, english})
+
+	lazy := sl.MustUse(l, lazyItalianSlot)
+	assert.DeepEqual(t, sl.UseImplementing[Greeter](l), []Greeter{english, lazy})
+}
+
+func TestUseVerbose(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return nil, errors.New("boom")
+	})
+
+	config, err := sl.UseVerbose(l, ConfigSlot)
+	assert.NilError(t, err)
+	assert.Equal(t, config.Foo, "foo")
+
+	_, err = sl.UseVerbose(l, LoggerSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+	assert.ErrorContains(t, err, "slot not registered, registered slots: [*sl_test.Config, *sl_test.ExampleService]")
+
+	_, err = sl.UseVerbose(l, ExampleServiceSlot)
+	assert.ErrorContains(t, err, "boom (slot registered but failed to resolve")
+}
+
+func TestProvideFuncSimple(t *testing.T) {
+	l := sl.New()
+
+	created := 0
+	sl.ProvideFuncSimple(l, ConfigSlot, func() (*Config, error) {
+		created++
+		return &Config{Foo: "foo"}, nil
+	})
+
+	assert.Equal(t, created, 0)
+	assert.Equal(t, sl.MustUse(l, ConfigSlot), sl.MustUse(l, ConfigSlot))
+	assert.Equal(t, created, 1)
+}
+
+func TestProvideFuncOK(t *testing.T) {
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	created := 0
+	sl.ProvideFuncOK(l, ExampleServiceSlot, func(l *sl.ServiceLocator) *ExampleService {
+		created++
+		return &ExampleService{Bar: sl.MustUse(l, ConfigSlot).Foo}
+	})
+	assert.Equal(t, created, 0)
+
+	service := sl.MustUse(l, ExampleServiceSlot)
+	assert.Equal(t, service.Bar, "foo")
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot), service)
+	assert.Equal(t, created, 1)
+}
+
+func TestProvideFactory(t *testing.T) {
+	factorySlot := sl.NewSlot[func() (*ExampleService, error)]()
+
+	l := sl.New()
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	built := 0
+	sl.ProvideFactory(l, factorySlot, func(l *sl.ServiceLocator) (func() (*ExampleService, error), error) {
+		built++
+		config := sl.MustUse(l, ConfigSlot)
+
+		return func() (*ExampleService, error) {
+			return &ExampleService{Bar: config.Foo}, nil
+		}, nil
+	})
+
+	newService := sl.MustUse(l, factorySlot)
+	service1, err := newService()
+	assert.NilError(t, err)
+	service2, err := sl.MustUse(l, factorySlot)()
+	assert.NilError(t, err)
+
+	assert.Equal(t, built, 1)
+	assert.Equal(t, service1.Bar, "foo")
+	assert.Assert(t, service1 != service2)
+}
+
+// exampleServiceDeps are the dependencies of [ExampleService]
+type exampleServiceDeps struct {
+	Config *Config
+	Logger *log.Logger
+}
+
+func newExampleService(deps exampleServiceDeps) (*ExampleService, error) {
+	if deps.Config.Foo == "" {
+		return nil, errors.New("empty foo")
+	}
+
+	return &ExampleService{Bar: deps.Config.Foo + " baz", Logger: deps.Logger}, nil
+}
+
+func TestProvideStruct(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.Provide(l, LoggerSlot, log.Default())
+	sl.ProvideStruct(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (exampleServiceDeps, error) {
+		config, logger, err := sl.Use2(l, ConfigSlot, LoggerSlot)
+		return exampleServiceDeps{config, logger}, err
+	}, newExampleService)
+
+	service := sl.MustUse(l, ExampleServiceSlot)
+	assert.Equal(t, service.Bar, "foo baz")
+	assert.Equal(t, service.Logger, log.Default())
+
+	_, err := newExampleService(exampleServiceDeps{Config: &Config{}})
+	assert.Error(t, err, "empty foo")
+
+	other := sl.New()
+	sl.ProvideStruct(other, ExampleServiceSlot, func(l *sl.ServiceLocator) (exampleServiceDeps, error) {
+		config, logger, err := sl.Use2(l, ConfigSlot, LoggerSlot)
+		return exampleServiceDeps{config, logger}, err
+	}, newExampleService)
+
+	_, err = sl.Use(other, ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+// repeat calls "fn" n times and returns the results, this is useful to create
+// many slots as their type can't be named outside of the package
+func repeat[T any](n int, fn func() T) []T {
+	values := make([]T, n)
+	for i := range values {
+		values[i] = fn()
+	}
+
+	return values
+}
+
+func TestConcurrentConfigureOnce(t *testing.T) {
+	l := sl.New()
+
+	// each slot depends on the previous one
+	slots := repeat(8, sl.NewSlot[int])
+	created := make([]atomic.Int32, len(slots))
+
+	for i := range slots {
+		i := i
+		sl.ProvideFunc(l, slots[i], func(l *sl.ServiceLocator) (int, error) {
+			created[i].Add(1)
+			time.Sleep(time.Millisecond)
+
+			if i == 0 {
+				return 0, nil
+			}
+
+			previous, err := sl.Use(l, slots[i-1])
+			return previous + 1, err
+		})
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 64; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			i := g % len(slots)
+			if v := sl.MustUse(l, slots[i]); v != i {
+				t.Errorf("slot %d resolved to %d", i, v)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for i := range created {
+		assert.Equal(t, created[i].Load(), int32(1))
+	}
+}
+
+func TestLocatorSetLogger(t *testing.T) {
+	l := sl.New()
+
+	var buf bytes.Buffer
+	restore := l.SetLogger(log.New(&buf, "", 0))
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.Provide(l, LoggerSlot, log.Default())
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		config, logger, err := sl.Use2(l, ConfigSlot, LoggerSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExampleService{Bar: config.Foo, Logger: logger}, nil
+	})
+
+	scope := l.Scope()
+	sl.MustUse(scope, ExampleServiceSlot)
+
+	assert.Assert(t, strings.Contains(buf.String(), "[slot: *sl_test.ExampleService] resolved dependencies: [*sl_test.Config, *log.Logger]\n"))
+
+	restore()
+	buf.Reset()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "bar"})
+	assert.Equal(t, buf.Len(), 0)
+}
+
+func TestTryUse(t *testing.T) {
+	countSlot := sl.NewSlot[int]()
+	enabledSlot := sl.NewSlot[bool]()
+
+	l := sl.New()
+
+	_, ok := sl.TryUse(l, countSlot)
+	assert.Equal(t, ok, false)
+	assert.Equal(t, sl.UseOr(l, countSlot, 42), 42)
+	assert.Equal(t, sl.UseOr(l, enabledSlot, true), true)
+
+	sl.Provide(l, countSlot, 0)
+	sl.Provide(l, enabledSlot, false)
+
+	count, ok := sl.TryUse(l, countSlot)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, count, 0)
+	assert.Equal(t, sl.UseOr(l, countSlot, 42), 0)
+
+	enabled, ok := sl.TryUse(l, enabledSlot)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, enabled, false)
+	assert.Equal(t, sl.UseOr(l, enabledSlot, true), false)
+}
+
+func TestMustUse2MustUse3(t *testing.T) {
+	l := sl.New()
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	logger := sl.Provide(l, LoggerSlot, log.Default())
+
+	c, lg := sl.MustUse2(l, ConfigSlot, LoggerSlot)
+	assert.Equal(t, c, config)
+	assert.Equal(t, lg, logger)
+
+	err := l.Recover(func() error {
+		sl.MustUse3(l, ConfigSlot, ExampleServiceSlot, LoggerSlot)
+		return nil
+	})
+
+	var slotErr *sl.SlotError
+	assert.Assert(t, errors.As(err, &slotErr))
+	assert.Equal(t, slotErr.TypeName, "*sl_test.ExampleService")
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+func TestNewSlotNamed(t *testing.T) {
+	primarySlot := sl.NewSlotNamed[*Config]("primary")
+	secondarySlot := sl.NewSlotNamed[*Config]("secondary")
+
+	l := sl.New()
+
+	_, err := sl.Use(l, primarySlot)
+	assert.Error(t, err, `no injected value for "primary" (*sl_test.Config)`)
+
+	err = l.Recover(func() error {
+		sl.MustUse(l, secondarySlot)
+		return nil
+	})
+	var slotErr *sl.SlotError
+	assert.Assert(t, errors.As(err, &slotErr))
+	assert.Equal(t, slotErr.TypeName, `"secondary" (*sl_test.Config)`)
+
+	sl.Provide(l, primarySlot, &Config{Foo: "primary"})
+	sl.ProvideFuncAll(l, map[any]func(*sl.ServiceLocator) (any, error){
+		secondarySlot: func(l *sl.ServiceLocator) (any, error) {
+			return &Config{Foo: "secondary"}, nil
+		},
+	})
+	sl.Provide(l, ConfigSlot, &Config{})
+
+	assert.Equal(t, sl.MustUse(l, secondarySlot).Foo, "secondary")
+	assert.Assert(t, sl.NewSlotNamed[*Config]("primary") != primarySlot)
+	assert.DeepEqual(t, l.TypeNameCollisions(), map[string]int{})
+
+	names := []string{}
+	for _, info := range l.Stats() {
+		names = append(names, info.TypeName)
+	}
+	assert.DeepEqual(t, names, []string{`"primary" (*sl_test.Config)`, `"secondary" (*go-sl_test.Config)`, "*sl_test.Config"})
+}
+
+func TestUseOrProvide(t *testing.T) {
+	l := sl.New()
+
+	config := sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+
+	c, err := sl.UseOrProvide(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		t.Fatal("should not be called")
+		return nil, nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, c, config)
+
+	var created atomic.Int32
+	createFunc := func(l *sl.ServiceLocator) (*ExampleService, error) {
+		created.Add(1)
+		return &ExampleService{Bar: "default"}, nil
+	}
+
+	var wg sync.WaitGroup
+	services := make([]*ExampleService, 16)
+	for i := range services {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			services[i], _ = sl.UseOrProvide(l, ExampleServiceSlot, createFunc)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, created.Load(), int32(1))
+	for _, service := range services {
+		assert.Equal(t, service, services[0])
+	}
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot), services[0])
+	assert.Equal(t, len(l.Stats()), 2)
+}
+
+func TestOnResolve(t *testing.T) {
+	l := sl.New()
+
+	assert.Assert(t, errors.Is(sl.OnResolve(l, ConfigSlot, func(l *sl.ServiceLocator, c *Config) error {
+		return nil
+	}), sl.ErrSlotNotFound))
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+
+	resolved := []string{}
+	assert.NilError(t, sl.OnResolve(l, ConfigSlot, func(l *sl.ServiceLocator, c *Config) error {
+		resolved = append(resolved, "first "+c.Foo)
+		return nil
+	}))
+
+	errMetrics := errors.New("metrics error")
+	assert.NilError(t, sl.OnResolve(l, ConfigSlot, func(l *sl.ServiceLocator, c *Config) error {
+		resolved = append(resolved, "second "+sl.MustUse(l, ConfigSlot).Foo)
+		return errMetrics
+	}))
+	assert.DeepEqual(t, resolved, []string{})
+
+	_, err := sl.Use(l, ConfigSlot)
+	assert.Assert(t, errors.Is(err, errMetrics))
+	assert.ErrorContains(t, err, "on resolve of *sl_test.Config: metrics error")
+
+	assert.Equal(t, sl.MustUse(l, ConfigSlot).Foo, "foo")
+	assert.DeepEqual(t, resolved, []string{"first foo", "second foo"})
+
+	assert.NilError(t, sl.OnResolve(l, ConfigSlot, func(l *sl.ServiceLocator, c *Config) error {
+		resolved = append(resolved, "third "+c.Foo)
+		return nil
+	}))
+	assert.DeepEqual(t, resolved, []string{"first foo", "second foo", "third foo"})
+}
+
+func TestWithSilentLogger(t *testing.T) {
+	l := sl.New()
+
+	var buf bytes.Buffer
+	defer l.SetLogger(log.New(&buf, "", 0))()
+
+	l.WithSilentLogger(func() {
+		sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	})
+	assert.Equal(t, buf.String(), "")
+
+	func() {
+		defer func() {
+			assert.Assert(t, recover() != nil)
+		}()
+
+		l.WithSilentLogger(func() {
+			sl.MustUse(l, ExampleServiceSlot)
+		})
+	}()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "bar"})
+	assert.Equal(t, buf.String(), "[slot: *sl_test.Config] provided value of type *sl_test.Config\n")
+}
+
+func TestRebind(t *testing.T) {
+	l := sl.New()
+
+	englishSlot := sl.NewSlot[*EnglishGreeter]()
+	sl.ProvideFunc(l, englishSlot, func(l *sl.ServiceLocator) (*EnglishGreeter, error) {
+		return &EnglishGreeter{Name: "World"}, nil
+	})
+
+	assert.NilError(t, sl.Rebind(l, englishSlot, GreeterSlot))
+	assert.Equal(t, sl.MustUse(l, GreeterSlot), Greeter(sl.MustUse(l, englishSlot)))
+
+	namedSlot := sl.NewSlot[NamedGreeter]()
+	err := sl.Rebind(l, englishSlot, namedSlot)
+	assert.Error(t, err, "rebinding *sl_test.EnglishGreeter to sl_test.NamedGreeter: slot of type *sl_test.EnglishGreeter is not assignable to sl_test.NamedGreeter")
+
+	_, err = sl.Use(l, namedSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	err = sl.Rebind(l, sl.NewSlot[*FrenchGreeter](), namedSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+}
+
+func TestProvideFunc2Out(t *testing.T) {
+	readerSlot := sl.NewSlot[*Config]()
+	writerSlot := sl.NewSlot[*ExampleService]()
+
+	provide := func() (*sl.ServiceLocator, *int) {
+		l := sl.New()
+
+		calls := 0
+		sl.ProvideFunc2Out(l, readerSlot, writerSlot, func(l *sl.ServiceLocator) (*Config, *ExampleService, error) {
+			calls++
+			return &Config{Foo: "reader"}, &ExampleService{Bar: "writer"}, nil
+		})
+
+		return l, &calls
+	}
+
+	l, calls := provide()
+	assert.Equal(t, sl.MustUse(l, readerSlot).Foo, "reader")
+	assert.Equal(t, sl.MustUse(l, writerSlot).Bar, "writer")
+	assert.Equal(t, *calls, 1)
+
+	l, calls = provide()
+	assert.Equal(t, sl.MustUse(l, writerSlot).Bar, "writer")
+	assert.Equal(t, sl.MustUse(l, readerSlot).Foo, "reader")
+	assert.Equal(t, *calls, 1)
+
+	l = sl.New()
+	errBroken := errors.New("broken")
+	sl.ProvideFunc2Out(l, readerSlot, writerSlot, func(l *sl.ServiceLocator) (*Config, *ExampleService, error) {
+		return nil, nil, errBroken
+	})
+
+	_, err := sl.Use(l, writerSlot)
+	assert.Assert(t, errors.Is(err, errBroken))
+
+	// resetting one output rebuilds both as a pair
+	l, calls = provide()
+	writer := sl.MustUse(l, writerSlot)
+	assert.Equal(t, sl.Reset(l, readerSlot), true)
+	reader := sl.MustUse(l, readerSlot)
+	assert.Equal(t, *calls, 2)
+	assert.Assert(t, sl.MustUse(l, writerSlot) != writer)
+	assert.Equal(t, *calls, 2)
+	assert.Equal(t, sl.MustUse(l, readerSlot), reader)
+
+	// resetting the other output rebuilds both as a pair too
+	l, calls = provide()
+	l.SetAutoCloser(true)
+	reader = sl.MustUse(l, readerSlot)
+	writer = sl.MustUse(l, writerSlot)
+	assert.Equal(t, sl.Reset(l, writerSlot), true)
+	assert.Assert(t, sl.MustUse(l, writerSlot) != writer)
+	assert.Assert(t, sl.MustUse(l, readerSlot) != reader)
+	assert.Equal(t, *calls, 2)
+
+	// an output provided separately doesn't match the other one
+	l, calls = provide()
+	sl.Override(l, readerSlot, &Config{Foo: "override"})
+	_, err = sl.Use(l, writerSlot)
+	assert.ErrorContains(t, err, "provided separately")
+	assert.Equal(t, *calls, 0)
+}
+
+func TestWithDefaultLogger(t *testing.T) {
+	l := sl.New(sl.WithDefaultLogger(LoggerSlot))
+
+	logger := sl.MustUse(l, LoggerSlot)
+	assert.Equal(t, logger.Writer(), os.Stderr)
+
+	custom := log.New(&bytes.Buffer{}, "", 0)
+	sl.Provide(l, LoggerSlot, custom)
+	assert.Equal(t, sl.MustUse(l, LoggerSlot), custom)
+}
@@ -0,0 +1,199 @@
+package sl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// SlotInfo is a snapshot of the state of a slot registered in a
+// [ServiceLocator], see [ServiceLocator.Stats].
+type SlotInfo struct {
+	// TypeName is the name of the type of the slot
+	TypeName string
+
+	// Lazy tells if this slot was provided with [ProvideFunc]
+	Lazy bool
+
+	// Configured tells if the slot has a value, this is always true for
+	// eagerly provided slots
+	Configured bool
+
+	// ConfigureDuration is how long the last configuration of a lazy slot
+	// took, this includes the time spent configuring its dependencies.
+	ConfigureDuration time.Duration
+
+	// Hits counts how many times the slot got used when already configured
+	Hits int
+
+	// Misses counts how many times the slot had to be configured when used,
+	// this is greater than one only for slots that got reset
+	Misses int
+
+	// Source is the "file:line" where the slot got provided, this is empty
+	// unless enabled with [ServiceLocator.SetCaptureSource]
+	Source string
+
+	// Module is the name of the module that provided the slot, this is empty
+	// unless provided with [ProvideBy] or [ProvideFuncBy]
+	Module string
+}
+
+// Stats returns information about all slots registered in this locator (not
+// including its parent scopes) in registration order.
+func (l *ServiceLocator) Stats() []SlotInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	infos := make([]SlotInfo, 0, len(l.slotKeys))
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		infos = append(infos, SlotInfo{
+			TypeName:          s.typeName,
+			Lazy:              s.configureFunc != nil,
+			Configured:        s.configured,
+			ConfigureDuration: s.configureDuration,
+			Hits:              s.hits,
+			Misses:            s.misses,
+			Source:            s.source,
+			Module:            s.module,
+		})
+	}
+
+	return infos
+}
+
+// maxLatencySamples is how many configuration durations are kept for each
+// slot, see [ServiceLocator.LatencyHistogram]
+const maxLatencySamples = 128
+
+// addLatency records a configuration duration dropping the oldest one if
+// there are already [maxLatencySamples]. The lock of the locator must be held.
+func (s *slotEntry) addLatency(d time.Duration) {
+	if len(s.latencies) == maxLatencySamples {
+		s.latencies = append(s.latencies[:0], s.latencies[1:]...)
+	}
+
+	s.latencies = append(s.latencies, d)
+}
+
+// LatencyHistogram returns the durations of the configurations of the lazy
+// slots of this locator (not including its parent scopes) by type name, in
+// the order they happened. Slots configured many times (for example after
+// being reset) have many samples, only the last 128 samples of each slot are
+// kept to bound memory. Slots sharing a type name have their samples merged,
+// see [ServiceLocator.TypeNameCollisions].
+//
+// This can be exported periodically to a monitoring system, see also
+// [ServiceLocator.Subscribe] to observe each configuration as it happens.
+func (l *ServiceLocator) LatencyHistogram() map[string][]time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	histogram := map[string][]time.Duration{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if len(s.latencies) > 0 {
+			histogram[s.typeName] = append(histogram[s.typeName], s.latencies...)
+		}
+	}
+
+	return histogram
+}
+
+// StartupReport returns a human readable table of all configured lazy slots
+// sorted by how long they took to configure, followed by the total summed
+// time and the wall-clock time between the first configuration start and the
+// last configuration end.
+//
+// As durations include the time spent configuring dependencies, the summed
+// time can be greater than the wall-clock time.
+func (l *ServiceLocator) StartupReport() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := []*slotEntry{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if s.configureFunc != nil && s.configured {
+			entries = append(entries, s)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].configureDuration > entries[j].configureDuration
+	})
+
+	var total time.Duration
+	var first, last time.Time
+	for _, s := range entries {
+		total += s.configureDuration
+
+		end := s.configureStart.Add(s.configureDuration)
+		if first.IsZero() || s.configureStart.Before(first) {
+			first = s.configureStart
+		}
+		if last.IsZero() || end.After(last) {
+			last = end
+		}
+	}
+
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "SLOT\tDURATION\n")
+	for _, s := range entries {
+		fmt.Fprintf(w, "%s\t%v\n", s.typeName, s.configureDuration)
+	}
+	fmt.Fprintf(w, "total (summed)\t%v\n", total)
+	fmt.Fprintf(w, "total (wall-clock)\t%v\n", last.Sub(first))
+	w.Flush()
+
+	return sb.String()
+}
+
+// UnusedSlots returns the type names of the lazy slots of this locator that
+// were never configured, in registration order. This can be used at the end of
+// a comprehensive test suite to find registrations that are never used.
+//
+// This doesn't configure any slot.
+func (l *ServiceLocator) UnusedSlots() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unused := []string{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if s.configureFunc != nil && !s.configured {
+			unused = append(unused, s.typeName)
+		}
+	}
+
+	return unused
+}
+
+// TypeNameCollisions returns the type names shared by more than one slot of
+// this locator, with the number of slots using each of them. Features keyed on
+// type names (like debug logs) are ambiguous for these slots.
+//
+// This doesn't configure any slot.
+func (l *ServiceLocator) TypeNameCollisions() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counts := map[string]int{}
+	for _, key := range l.slotKeys {
+		counts[l.providers[key].typeName]++
+	}
+
+	collisions := map[string]int{}
+	for typeName, count := range counts {
+		if count > 1 {
+			collisions[typeName] = count
+		}
+	}
+
+	return collisions
+}
@@ -0,0 +1,138 @@
+package sl_test
+
+import (
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestStats(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		time.Sleep(time.Millisecond)
+		return &ExampleService{}, nil
+	})
+
+	stats := l.Stats()
+	assert.Equal(t, len(stats), 2)
+	assert.Equal(t, stats[0].TypeName, "*sl_test.Config")
+	assert.Equal(t, stats[0].Lazy, false)
+	assert.Equal(t, stats[0].Configured, true)
+	assert.Equal(t, stats[1].TypeName, "*sl_test.ExampleService")
+	assert.Equal(t, stats[1].Configured, false)
+
+	sl.MustUse(l, ExampleServiceSlot)
+
+	sl.MustUse(l, ExampleServiceSlot)
+	sl.MustUse(l, ConfigSlot)
+
+	stats = l.Stats()
+	assert.Equal(t, stats[1].Configured, true)
+	assert.Assert(t, stats[1].ConfigureDuration >= time.Millisecond)
+
+	assert.Equal(t, stats[0].Hits, 1)
+	assert.Equal(t, stats[0].Misses, 0)
+	assert.Equal(t, stats[1].Hits, 1)
+	assert.Equal(t, stats[1].Misses, 1)
+
+	sl.Reset(l, ExampleServiceSlot)
+	sl.MustUse(l, ExampleServiceSlot)
+
+	assert.Equal(t, l.Stats()[1].Misses, 2)
+}
+
+func TestStartupReport(t *testing.T) {
+	l := sl.New()
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{}, nil
+	})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		time.Sleep(time.Millisecond)
+		return &ExampleService{}, nil
+	})
+
+	sl.MustUse(l, ConfigSlot)
+	sl.MustUse(l, ExampleServiceSlot)
+
+	lines := strings.Split(l.StartupReport(), "\n")
+	assert.Assert(t, strings.HasPrefix(lines[1], "*sl_test.ExampleService "))
+	assert.Assert(t, strings.HasPrefix(lines[2], "*sl_test.Config "))
+	assert.Assert(t, strings.HasPrefix(lines[3], "total (summed) "))
+	assert.Assert(t, strings.HasPrefix(lines[4], "total (wall-clock) "))
+}
+
+func TestUnusedSlots(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, LoggerSlot, log.Default())
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{}, nil
+	})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{}, nil
+	})
+
+	assert.DeepEqual(t, l.UnusedSlots(), []string{"*sl_test.Config", "*sl_test.ExampleService"})
+
+	sl.MustInvoke(l, ConfigSlot)
+
+	assert.DeepEqual(t, l.UnusedSlots(), []string{"*sl_test.ExampleService"})
+}
+
+func TestTypeNameCollisions(t *testing.T) {
+	primaryConfigSlot := sl.NewSlot[*Config]()
+	secondaryConfigSlot := sl.NewSlot[*Config]()
+
+	l := sl.New()
+
+	sl.Provide(l, LoggerSlot, log.Default())
+	sl.Provide(l, ConfigSlot, &Config{Foo: "foo"})
+	assert.DeepEqual(t, l.TypeNameCollisions(), map[string]int{})
+
+	sl.Provide(l, primaryConfigSlot, &Config{Foo: "primary"})
+	sl.ProvideFunc(l, secondaryConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "secondary"}, nil
+	})
+
+	assert.DeepEqual(t, l.TypeNameCollisions(), map[string]int{"*sl_test.Config": 3})
+	assert.DeepEqual(t, l.UnusedSlots(), []string{"*sl_test.Config"})
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	l := sl.New()
+
+	sl.Provide(l, LoggerSlot, log.Default())
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		time.Sleep(time.Millisecond)
+		return &Config{}, nil
+	})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{}, nil
+	})
+
+	assert.DeepEqual(t, l.LatencyHistogram(), map[string][]time.Duration{})
+
+	sl.MustInvoke(l, ConfigSlot)
+	sl.Reset(l, ConfigSlot)
+	sl.MustInvoke(l, ConfigSlot)
+
+	histogram := l.LatencyHistogram()
+	assert.Equal(t, len(histogram), 1)
+	assert.Equal(t, len(histogram["*sl_test.Config"]), 2)
+	for _, d := range histogram["*sl_test.Config"] {
+		assert.Assert(t, d >= time.Millisecond)
+	}
+
+	for i := 0; i < 200; i++ {
+		sl.MustInvoke(l, ExampleServiceSlot)
+		sl.Reset(l, ExampleServiceSlot)
+	}
+	assert.Equal(t, len(l.LatencyHistogram()["*sl_test.ExampleService"]), 128)
+}
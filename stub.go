@@ -0,0 +1,52 @@
+package sl
+
+// UseStubbed resolves the given slot for unit testing it in isolation. The
+// slot is configured again, ignoring any cached value, against an overlay
+// scope of "l" (see [ServiceLocator.Scope]) where "stubs" provide fake
+// dependencies, for example
+//
+//	service, err := sl.UseStubbed(l, ServiceSlot, func(l *sl.ServiceLocator) {
+//		sl.Provide(l, DatabaseSlot, fakeDatabase)
+//	})
+//
+// Dependencies not stubbed are resolved from "l" and the ones missing there
+// too resolve to their zero value instead of returning [ErrSlotNotFound].
+// Zero values are rarely useful for interfaces (they are nil), so interface
+// dependencies used by the service should be stubbed explicitly.
+//
+// Only the dependencies used directly by the slot are resolved from the
+// overlay, slots of "l" used by the service are configured against "l" as
+// usual. Values created in the overlay are not cached in "l" and their
+// cleanups are not called.
+func UseStubbed[T any](l *ServiceLocator, slotKey slot[T], stubs ...func(*ServiceLocator)) (T, error) {
+	typeName := slotName(slotKey)
+
+	overlay := l.Scope()
+	for _, stub := range stubs {
+		stub(overlay)
+	}
+
+	overlay.mu.Lock()
+	if _, ok := overlay.providers[slotKey]; !ok {
+		s, _, ok := l.lookupProvider(slotKey)
+		if !ok {
+			overlay.mu.Unlock()
+			return zero[T](), notFoundError(slotKey)
+		}
+
+		if s.configureFunc != nil {
+			overlay.putProvider(slotKey, &slotEntry{
+				typeName:      s.typeName,
+				configureFunc: s.configureFunc,
+				source:        s.source,
+				module:        s.module,
+			})
+		}
+	}
+	overlay.stubMissing = true
+	overlay.mu.Unlock()
+
+	l.logf(`[slot: %s] using with stubs`, typeName)
+
+	return useSlotValue(overlay, slotKey)
+}
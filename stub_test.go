@@ -0,0 +1,56 @@
+package sl_test
+
+import (
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestUseStubbed(t *testing.T) {
+	l := sl.New()
+
+	_, err := sl.UseStubbed(l, ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		config, err := sl.Use(l, ConfigSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		logger, err := sl.Use(l, LoggerSlot)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExampleService{Bar: config.Foo, Logger: logger}, nil
+	})
+
+	service, err := sl.UseStubbed(l, ExampleServiceSlot, func(l *sl.ServiceLocator) {
+		sl.Provide(l, ConfigSlot, &Config{Foo: "stub"})
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, service.Bar, "stub")
+	assert.Assert(t, service.Logger == nil)
+
+	// the locator itself is left untouched
+	_, err = sl.Use(l, ExampleServiceSlot)
+	assert.Assert(t, errors.Is(err, sl.ErrSlotNotFound))
+
+	sl.Provide(l, ConfigSlot, &Config{Foo: "real"})
+	sl.Provide(l, LoggerSlot, log.Default())
+
+	real := sl.MustUse(l, ExampleServiceSlot)
+	assert.Equal(t, real.Bar, "real")
+
+	service, err = sl.UseStubbed(l, ExampleServiceSlot, func(l *sl.ServiceLocator) {
+		sl.Provide(l, ConfigSlot, &Config{Foo: "stub"})
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, service.Bar, "stub")
+	assert.Equal(t, service.Logger, log.Default())
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot), real)
+}
@@ -0,0 +1,101 @@
+package sl
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// SetWarmUpConcurrency sets the maximum number of slots configured at the
+// same time by [ServiceLocator.WarmUp], this bounds the pressure on
+// downstream systems at startup (for example how many database connections
+// get opened at once). With "n" equal to one slots are configured
+// sequentially, zero or less goes back to the default of
+// [runtime.GOMAXPROCS] workers. This applies to child scopes created
+// afterwards.
+func (l *ServiceLocator) SetWarmUpConcurrency(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.settings.warmUpConcurrency = n
+}
+
+// WarmUp configures all the lazy slots of this locator (not including its
+// parent scopes) using a pool of workers, see
+// [ServiceLocator.SetWarmUpConcurrency], so that slow constructors run in
+// parallel at startup instead of on first use. All slots are configured even
+// if some fail, the returned error joins all their errors. Slots behind a
+// barrier (see [ServiceLocator.Barrier]) are not configured and reported as
+// errors.
+//
+// Dependencies are configured by the worker of the slot using them, so each
+// worker can be running more than one constructor at a time. Scoped slots and
+// slots derived from the context are skipped. With
+// [ServiceLocator.SetDeterministic] slots are configured one at a time in
+// registration order, and with [ServiceLocator.SetSingleGoroutine] they are
+// configured on the calling goroutine in the same order.
+func (l *ServiceLocator) WarmUp() error {
+	l.mu.Lock()
+	entries := []*slotEntry{}
+	checkErrs := []error{}
+	for _, key := range l.slotKeys {
+		s := l.providers[key]
+		if s.configureFunc == nil || s.configured || s.scoped || s.extractFunc != nil {
+			continue
+		}
+
+		// workers run on other goroutines, so the checks are done here
+		if err := l.checkUse(key, s.typeName); err != nil {
+			checkErrs = append(checkErrs, err)
+			continue
+		}
+
+		entries = append(entries, s)
+	}
+
+	workers := l.settings.warmUpConcurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if l.settings.deterministic {
+		workers = 1
+	}
+
+	// a locator bound to a goroutine can't be used by the workers
+	inline := l.settings.goroutineID != 0
+	l.mu.Unlock()
+
+	errs := make([]error, len(entries))
+	if inline {
+		l.logf(`[warm-up] configuring %d slots on the calling goroutine`, len(entries))
+
+		for i, s := range entries {
+			_, errs[i] = s.ensureConfigured(l)
+		}
+
+		return errors.Join(append(checkErrs, errs...)...)
+	}
+
+	l.logf(`[warm-up] configuring %d slots with %d workers`, len(entries), workers)
+
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				_, errs[i] = entries[i].ensureConfigured(l)
+			}
+		}()
+	}
+
+	for i := range entries {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return errors.Join(append(checkErrs, errs...)...)
+}
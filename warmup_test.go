@@ -0,0 +1,71 @@
+package sl_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+func TestWarmUp(t *testing.T) {
+	for _, n := range []int{1, 3} {
+		l := sl.New()
+		l.SetWarmUpConcurrency(n)
+
+		var running, maxRunning atomic.Int32
+		for i := 0; i < 8; i++ {
+			sl.ProvideFunc(l, sl.NewSlot[int](), func(l *sl.ServiceLocator) (int, error) {
+				current := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					max := maxRunning.Load()
+					if current <= max || maxRunning.CompareAndSwap(max, current) {
+						break
+					}
+				}
+
+				time.Sleep(5 * time.Millisecond)
+				return 0, nil
+			})
+		}
+
+		assert.NilError(t, l.WarmUp())
+		assert.Assert(t, maxRunning.Load() <= int32(n))
+		assert.DeepEqual(t, l.UnusedSlots(), []string{})
+	}
+}
+
+func TestWarmUpError(t *testing.T) {
+	l := sl.New()
+
+	errBroken := errors.New("broken")
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return nil, errBroken
+	})
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+
+	err := l.WarmUp()
+	assert.Assert(t, errors.Is(err, errBroken))
+	assert.DeepEqual(t, l.UnusedSlots(), []string{"*sl_test.ExampleService"})
+}
+
+func TestWarmUpSingleGoroutine(t *testing.T) {
+	l := sl.New()
+	l.SetSingleGoroutine(true)
+
+	sl.ProvideFunc(l, ConfigSlot, func(l *sl.ServiceLocator) (*Config, error) {
+		return &Config{Foo: "foo"}, nil
+	})
+	sl.ProvideFunc(l, ExampleServiceSlot, func(l *sl.ServiceLocator) (*ExampleService, error) {
+		return &ExampleService{Bar: sl.MustUse(l, ConfigSlot).Foo}, nil
+	})
+
+	assert.NilError(t, l.WarmUp())
+	assert.Equal(t, sl.MustUse(l, ExampleServiceSlot).Bar, "foo")
+}
@@ -0,0 +1,67 @@
+//go:build go1.24
+
+package sl
+
+import (
+	"fmt"
+	"reflect"
+	"weak"
+)
+
+// SubscribeWeak is like [Subscribe] but the listener only lives as long as
+// "owner", which must be a non-nil pointer. The locator keeps a weak
+// reference to "owner" (using the [weak] package of Go 1.24) and once it gets
+// garbage collected the listener is removed on the next dispatch of the hook.
+// This avoids leaking listeners of short lived objects when hooks are used as
+// a runtime event bus, for example
+//
+//	sl.SubscribeWeak(l, UserCreatedHook, conn, func(l *sl.ServiceLocator, u *User) error {
+//		return notify(l, u)
+//	})
+//
+// The listener must not reference "owner" itself, otherwise the locator keeps
+// it reachable and it never gets collected.
+//
+// This function is only defined when building with Go 1.24 or later, as it
+// depends on the [weak] package, while the rest of the package only requires
+// Go 1.20.
+func SubscribeWeak[T any](l *ServiceLocator, topic hook[T], owner any, fn Hook[T]) {
+	typeName := getTypeName[T]()
+	if fn == nil {
+		l.logf(`[hook: %s] skipped nil listener`, typeName)
+		return
+	}
+
+	v := reflect.ValueOf(owner)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		panic(fmt.Sprintf(`weak listener owner must be a non-nil pointer, got %T`, owner))
+	}
+
+	// a weak pointer to the first byte keeps track of the whole object
+	ref := weak.Make((*byte)(v.UnsafePointer()))
+
+	l.logf(`[hook: %s] subscribing weak listener`, typeName)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.hooks[topic]
+	if !ok {
+		entry = &hookEntry{typeName: typeName, payloadType: payloadTypeOf[T](), inherit: true}
+		l.hooks[topic] = entry
+	}
+
+	if err := entry.checkPayload(payloadTypeOf[T]()); err != nil {
+		l.logf(`[hook: %s] skipped listener: %v`, typeName, err)
+		return
+	}
+
+	if entry.alive == nil {
+		entry.alive = make([]func() bool, len(entry.listeners))
+	}
+
+	entry.listeners = append(entry.listeners, toAnyListener(fn))
+	entry.alive = append(entry.alive, func() bool {
+		return ref.Value() != nil
+	})
+}
@@ -0,0 +1,62 @@
+//go:build go1.24
+
+package sl_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/aziis98/go-sl"
+	"gotest.tools/assert"
+)
+
+type subscriber struct {
+	name *string
+}
+
+func TestSubscribeWeak(t *testing.T) {
+	l := sl.New()
+
+	topic := sl.NewHook[string]()
+
+	calls := map[string]int{}
+	listener := func(name string) sl.Hook[string] {
+		return func(l *sl.ServiceLocator, event string) error {
+			calls[name]++
+			return nil
+		}
+	}
+
+	name := "owner"
+	owner := &subscriber{&name}
+
+	sl.Subscribe(l, topic, listener("strong"))
+	sl.SubscribeWeak(l, topic, owner, listener("weak"))
+
+	assert.NilError(t, sl.Publish(l, topic, "first"))
+	assert.DeepEqual(t, calls, map[string]int{"strong": 1, "weak": 1})
+
+	runtime.KeepAlive(owner)
+	owner = nil
+	runtime.GC()
+
+	assert.NilError(t, sl.Publish(l, topic, "second"))
+	assert.DeepEqual(t, calls, map[string]int{"strong": 2, "weak": 1})
+
+	results, err := sl.UseHookCollect(l, topic, "third")
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+}
+
+func TestSubscribeWeakNotPointer(t *testing.T) {
+	l := sl.New()
+
+	topic := sl.NewHook[string]()
+
+	defer func() {
+		assert.Equal(t, recover(), "weak listener owner must be a non-nil pointer, got sl_test.subscriber")
+	}()
+	sl.SubscribeWeak(l, topic, subscriber{}, func(l *sl.ServiceLocator, event string) error {
+		return nil
+	})
+}